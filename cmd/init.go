@@ -7,6 +7,8 @@ import (
 	"os"
 	"path/filepath"
 
+	"cost-explorer/internal/config"
+
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
 )
@@ -24,24 +26,9 @@ func init() {
 	rootCmd.AddCommand(initCmd)
 }
 
-func getConfigDir() (string, error) {
-	// Check for XDG_CONFIG_HOME first
-	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
-		return filepath.Join(configHome, "cost-explorer"), nil
-	}
-
-	// Fall back to ~/.config on Unix-like systems
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-
-	return filepath.Join(homeDir, ".config", "cost-explorer"), nil
-}
-
 func initDatabase() {
 	// Get XDG config directory
-	configDir, err := getConfigDir()
+	configDir, err := config.Dir()
 	if err != nil {
 		log.Fatalf("Failed to get config directory: %v", err)
 	}
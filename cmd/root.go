@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"cost-explorer/internal/app"
 	"cost-explorer/internal/aws"
+	"cost-explorer/internal/config"
 	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
 
 	"github.com/spf13/cobra"
 )
@@ -15,19 +20,88 @@ var rootCmd = &cobra.Command{
 	Use:   "cost-explorer",
 	Short: "AWS Cost Explorer TUI application",
 	Long:  "A terminal user interface for exploring AWS costs and usage data",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		metric, err := aws.ParseMetric(metricName)
+		if err != nil {
+			return err
+		}
+		dateRange, err := parseDateRange()
+		if err != nil {
+			return err
+		}
+		if historyMonths < 1 || historyMonths > aws.MaxHistoryMonths {
+			return fmt.Errorf("--months must be between 1 and %d", aws.MaxHistoryMonths)
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := aws.SetServiceNames(cfg.ServiceNames); err != nil {
+			return err
+		}
+		if err := ui.SetMoneyFormat(cfg.Currency); err != nil {
+			return err
+		}
+
 		// This is the default behavior - start the TUI
-		startTUI()
+		startTUI(types.Query{Profile: profileName, Metric: metric, Range: dateRange, Months: historyMonths})
+		return nil
 	},
 }
 
+var (
+	// profileName and regionName select the AWS profile and region of the client
+	profileName string
+	regionName  string
+	// useFixtures runs the TUI against canned data instead of a live AWS account
+	useFixtures bool
+	// metricName is the cost metric selected on startup
+	metricName string
+	// historyMonths is how many months monthly views show without a range
+	historyMonths int
+	// rangePreset, fromDate and toDate select the initial date range
+	rangePreset string
+	fromDate    string
+	toDate      string
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "AWS shared config profile (default $AWS_PROFILE or \"default\")")
+	rootCmd.Flags().StringVar(&regionName, "region", "", "AWS region (default from the profile or environment)")
+	rootCmd.Flags().BoolVar(&useFixtures, "fixtures", false, "Use built-in fixture data instead of calling AWS")
+	rootCmd.Flags().StringVar(&metricName, "metric", aws.DefaultMetric, "Cost metric: "+strings.Join(aws.Metrics, ", "))
+
+	presetIDs := make([]string, len(aws.RangePresets))
+	for i, preset := range aws.RangePresets {
+		presetIDs[i] = preset.ID
+	}
+	rootCmd.Flags().IntVar(&historyMonths, "months", aws.DefaultHistoryMonths, fmt.Sprintf("Months of history in monthly views when no range is set (1-%d)", aws.MaxHistoryMonths))
+	rootCmd.Flags().StringVar(&rangePreset, "range", "", "Date range preset: "+strings.Join(presetIDs, ", "))
+	rootCmd.Flags().StringVar(&fromDate, "from", "", "Start date of a custom range (YYYY-MM-DD)")
+	rootCmd.Flags().StringVar(&toDate, "to", "", "End date of a custom range, inclusive (YYYY-MM-DD)")
+	rootCmd.MarkFlagsRequiredTogether("from", "to")
+	rootCmd.MarkFlagsMutuallyExclusive("range", "from")
+}
+
+// parseDateRange builds the initial date range from the flags
+func parseDateRange() (types.DateRange, error) {
+	switch {
+	case rangePreset != "":
+		return aws.PresetRange(rangePreset, time.Now())
+	case fromDate != "":
+		return aws.ParseRange(fromDate, toDate)
+	}
+	return types.DateRange{}, nil
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func startTUI() {
+func startTUI(query types.Query) {
 	// Setup logging to file to avoid interfering with TUI
 	logFile, err := os.OpenFile("cost-explorer.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
@@ -36,15 +110,17 @@ func startTUI() {
 	defer logFile.Close()
 	log.SetOutput(logFile)
 
-	// Create AWS client
-	client, err := aws.NewClient()
+	// Create AWS client, or a fake one serving fixtures
+	client, err := newClient(query.Profile)
 	if err != nil {
 		log.Fatalf("Unable to create AWS client: %v", err)
 	}
 
 	// Create app state with client
 	initialState := &types.AppState{
-		Client: client,
+		Client:    client,
+		NewClient: newClient,
+		Query:     query,
 	}
 
 	// Create and run the application
@@ -53,3 +129,12 @@ func startTUI() {
 		panic(err)
 	}
 }
+
+// newClient creates the client of a profile in the selected region, or a fake
+// one serving fixtures
+func newClient(profile string) (types.CostExplorerAPI, error) {
+	if useFixtures {
+		return aws.NewFakeClient(), nil
+	}
+	return aws.NewClient(profile, regionName)
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// anomaliesSection is the menu label of the Anomalies view, whose unreviewed
+// anomalies are counted in the header
+const anomaliesSection = "Anomalies"
+
+// anomalyFeedbackPage is the page name of the anomaly feedback picker
+const anomalyFeedbackPage = "anomalyfeedback"
+
+// newAnomalyView creates the Anomalies view, which ignores the metric, filter
+// and record types; 'a' answers whether the selected anomaly is one
+func newAnomalyView() *detailTableView {
+	var v *detailTableView
+	v = newDetailTableView(anomaliesSection, []string{aws.AnomalyImpactColumn, aws.AnomalyPercentColumn}, aws.GetAnomalyData,
+		KeyBinding{Key: 'a', Help: "feedback", Action: func(state *types.AppState) {
+			OpenAnomalyFeedback(state, v)
+		}})
+	v.scope = historyScope
+	return v
+}
+
+// OpenAnomalyFeedback asks whether the selected anomaly is one and sends the
+// answer to Cost Explorer, then reloads the anomalies and the header badge
+func OpenAnomalyFeedback(state *types.AppState, view *detailTableView) {
+	row, _ := state.MainTable.GetSelection()
+	key := view.RowKey(row)
+	if len(key) == 0 {
+		state.StatusBar.SetText("[yellow]Select an anomaly to give feedback on[-]")
+		return
+	}
+	anomalyID := key[0]
+
+	previousFocus := state.App.GetFocus()
+	closePicker := func() {
+		state.Pages.RemovePage(anomalyFeedbackPage)
+		state.App.SetFocus(previousFocus)
+	}
+
+	list := ui.CreatePickerList("Is this an anomaly?", aws.AnomalyFeedbackOptions, "", func(feedback string) {
+		closePicker()
+		state.StatusBar.SetText("[yellow]Sending feedback...[-]")
+
+		q := state.Query
+		go func() {
+			err := aws.SubmitAnomalyFeedback(queryClient(state, q), anomalyID, feedback)
+			if err == nil {
+				log.Printf("Anomaly %s marked %q", anomalyID, feedback)
+				loadSection(state, view.Name(), q)
+			}
+
+			state.App.QueueUpdateDraw(func() {
+				if err != nil {
+					state.StatusBar.SetText(errorStatus(view.Name(), err))
+					return
+				}
+				if state.CurrentSection == view.Name() {
+					showSection(state, view.Name())
+				}
+				setHeader(state, "[green]AWS Cost Explorer")
+				state.StatusBar.SetText(fmt.Sprintf("[green]✓[-] Feedback sent: %s", feedback))
+			})
+		}()
+	}, closePicker)
+
+	state.Pages.AddPage(anomalyFeedbackPage, ui.CenteredModal(list, 40, len(aws.AnomalyFeedbackOptions)+2), true, true)
+	state.App.SetFocus(list)
+}
+
+// anomalyBadge returns a header badge counting the anomalies without
+// feedback, or an empty string when there are none or none are loaded
+func anomalyBadge(state *types.AppState) string {
+	data, exists := cachedData(state, anomaliesSection)
+	if !exists {
+		return ""
+	}
+
+	column := -1
+	for i, c := range data.Columns {
+		if c.Title == aws.AnomalyFeedbackColumn {
+			column = i
+		}
+	}
+	if column < 0 {
+		return ""
+	}
+
+	count := 0
+	for _, cells := range data.Rows {
+		if column < len(cells) && cells[column].Text == "" {
+			count++
+		}
+	}
+	switch count {
+	case 0:
+		return ""
+	case 1:
+		return "[red::b]🚨 1 anomaly to review[-::-]"
+	default:
+		return fmt.Sprintf("[red::b]🚨 %d anomalies to review[-::-]", count)
+	}
+}
@@ -1,8 +1,10 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
 
 	"cost-explorer/internal/aws"
@@ -12,170 +14,423 @@ import (
 	"github.com/rivo/tview"
 )
 
+// mainPage is the page name of the main grid; modals are added above it
+const mainPage = "main"
+
 // CreateApp initializes and returns the application state
 func CreateApp(client *types.AppState) *types.AppState {
 	ui.SetupRosePineTheme()
 
+	query := client.Query
+	query.Profile = profileKey(query.Profile)
+
 	state := &types.AppState{
-		Client:    client.Client,
-		DataCache: make(map[string]types.CostData),
+		Client:         client.Client,
+		Clients:        map[string]types.CostExplorerAPI{query.Profile: client.Client},
+		NewClient:      client.NewClient,
+		Query:          query,
+		CurrentSection: "Dashboard",
+		DataCache:      make(map[string]types.CostData),
+		ErrorCache:     make(map[string]error),
+		Fetching:       make(map[string]bool),
 	}
 
 	// Create components
 	state.Header = ui.CreateHeader()
 	state.Footer = ui.CreateFooter()
+	state.StatusBar = ui.CreateStatusBar()
 	state.MainTable = ui.CreateMainTable()
+	state.DetailPane = ui.CreateDetailPane()
 
 	// Menu with callback to update content
-	state.Menu = ui.CreateMenu(func(selection string) {
+	state.Menu = ui.CreateMenu(registry.Names(), func(selection string) {
 		UpdateContent(state, selection)
 	})
 
-	// Setup grid
+	// Setup grid, wrapped in pages so modals can be shown over it
 	state.Grid = ui.SetupGrid(state)
+	state.Pages = tview.NewPages().AddPage(mainPage, state.Grid, true, true)
 
 	// Create application
 	state.App = tview.NewApplication().
-		SetRoot(state.Grid, true).
+		SetRoot(state.Pages, true).
 		SetFocus(state.Menu)
 
 	// Setup key bindings
 	SetupKeyBindings(state, UpdateContent)
 
+	// Keep the detail pane on the selected row
+	state.MainTable.SetSelectionChangedFunc(func(row, column int) {
+		updateDetailPane(state)
+	})
+
 	// Show initial loading state
 	initialData := types.CostData{
-		Title: "Welcome to AWS Cost Explorer",
-		Rows: [][]string{
-			{"Status", "Message"},
-			{"Initializing", "Loading cost data..."},
+		Title:   "Welcome to AWS Cost Explorer",
+		Columns: []types.Column{{Title: "Status"}, {Title: "Message"}},
+		Rows: [][]types.Cell{
+			{types.TextCell("Initializing"), types.TextCell("Loading cost data...")},
 		},
 	}
 	ui.PopulateTable(state.MainTable, initialData)
 
+	if state.Query.Metric == "" {
+		state.Query.Metric = aws.DefaultMetric
+	}
+	if state.Query.Months <= 0 {
+		state.Query.Months = aws.DefaultHistoryMonths
+	}
+
 	// Load all data concurrently on startup
-	go LoadAllData(state)
+	go LoadAllData(state, state.Query)
 
 	return state
 }
 
-// LoadAllData fetches all cost data concurrently and stores it in memory
-func LoadAllData(state *types.AppState) {
+// cacheKey identifies a section's data by the query settings its view
+// depends on and the view's own settings, if it has any
+func cacheKey(section string, q types.Query) string {
+	view, exists := registry.Lookup(section)
+	if !exists {
+		return section + "|" + q.Key()
+	}
+
+	key := section + "|" + view.QueryKey(q)
+	if variant, ok := view.(variantView); ok {
+		key += "|" + variant.Variant()
+	}
+	return key
+}
+
+// fetchSection calls the registered view's fetch function for a section
+func fetchSection(state *types.AppState, section string, q types.Query) (types.CostData, error) {
+	view, exists := registry.Lookup(section)
+	if !exists {
+		return types.CostData{}, fmt.Errorf("unknown section %q", section)
+	}
+	return view.Fetch(queryClient(state, q), q)
+}
+
+// startFetch marks a section as being fetched for a query. It reports false
+// when the section is already cached or being fetched, so that quick setting
+// changes do not send the same requests twice.
+func startFetch(state *types.AppState, section string, q types.Query) bool {
+	key := cacheKey(section, q)
+
+	state.CacheMutex.Lock()
+	defer state.CacheMutex.Unlock()
+	_, hasData := state.DataCache[key]
+	_, hasErr := state.ErrorCache[key]
+	if hasData || hasErr || state.Fetching[key] {
+		return false
+	}
+	state.Fetching[key] = true
+	return true
+}
+
+// queryClient returns the client of the query's profile, so fetches started
+// before a profile switch finish against the profile they were for
+func queryClient(state *types.AppState, q types.Query) types.CostExplorerAPI {
+	state.CacheMutex.RLock()
+	defer state.CacheMutex.RUnlock()
+	if client, exists := state.Clients[q.Profile]; exists {
+		return client
+	}
+	return state.Client
+}
+
+// cachedData returns a section's data for the active query, if loaded
+func cachedData(state *types.AppState, section string) (types.CostData, bool) {
+	key := cacheKey(section, state.Query)
+
+	state.CacheMutex.RLock()
+	defer state.CacheMutex.RUnlock()
+	data, exists := state.DataCache[key]
+	return data, exists
+}
+
+// loadSection fetches a section and stores its data or error in the cache
+func loadSection(state *types.AppState, section string, q types.Query) {
+	log.Printf("Fetching %s data...", section)
+	// Take the key first, as the view's settings may change while fetching
+	key := cacheKey(section, q)
+	data, err := fetchSection(state, section, q)
+
+	state.CacheMutex.Lock()
+	if err != nil {
+		state.ErrorCache[key] = err
+		delete(state.DataCache, key)
+	} else {
+		state.DataCache[key] = data
+		delete(state.ErrorCache, key)
+	}
+	delete(state.Fetching, key)
+	state.CacheMutex.Unlock()
+
+	if err != nil {
+		log.Printf("Failed to load %s data: %v", section, err)
+	} else {
+		log.Printf("Loaded %s data", section)
+	}
+}
+
+// LoadAllData fetches all cost data for a query concurrently and stores it
+// in memory. Sections already cached or being fetched for the query are
+// skipped.
+func LoadAllData(state *types.AppState, q types.Query) {
 	log.Printf("Starting concurrent data loading...")
 
 	state.App.QueueUpdateDraw(func() {
-		state.Header.SetText("[yellow]Loading all cost data...[-]")
+		setHeader(state, "[yellow]Loading all cost data...[-]")
 	})
 
 	var wg sync.WaitGroup
-	sections := []string{"Dashboard", "By Service", "By Usage Type", "By Region"}
 
 	// Start all API calls concurrently
-	for _, section := range sections {
+	for _, section := range registry.Names() {
+		if !startFetch(state, section, q) {
+			continue
+		}
+
 		wg.Add(1)
 		go func(sectionName string) {
 			defer wg.Done()
+			loadSection(state, sectionName, q)
 
-			log.Printf("Fetching %s data...", sectionName)
-			var data types.CostData
-
-			switch sectionName {
-			case "Dashboard":
-				data = aws.GetDashboardData(state.Client)
-			case "By Service":
-				data = aws.GetServiceData(state.Client)
-			case "By Region":
-				data = aws.GetRegionData(state.Client)
-			case "By Usage Type":
-				data = aws.GetUsageTypeData(state.Client)
-			}
-
-			// Store data in memory
-			state.CacheMutex.Lock()
-			state.DataCache[sectionName] = data
-			state.CacheMutex.Unlock()
-
-			log.Printf("Loaded %s data", sectionName)
+			// Show the section as soon as it arrives if the user is waiting on it
+			state.App.QueueUpdateDraw(func() {
+				if state.CurrentSection == sectionName && cacheKey(sectionName, state.Query) == cacheKey(sectionName, q) {
+					showSection(state, sectionName)
+				}
+			})
 		}(section)
 	}
 
 	// Wait for all goroutines to complete
 	wg.Wait()
-	log.Printf("All data loaded successfully!")
+	log.Printf("All data loading finished")
 
-	// Show dashboard by default
+	// Show the current section, the dashboard by default
 	state.App.QueueUpdateDraw(func() {
-		state.Header.SetText("[green]AWS Cost Explorer - Dashboard")
-		state.CacheMutex.RLock()
-		if dashboardData, exists := state.DataCache["Dashboard"]; exists {
-			ui.PopulateTable(state.MainTable, dashboardData)
+		if state.Query.Key() != q.Key() {
+			return // Settings changed while loading
 		}
-		state.CacheMutex.RUnlock()
+		setHeader(state, fmt.Sprintf("[green]AWS Cost Explorer - %s", state.CurrentSection))
+		showSection(state, state.CurrentSection)
 	})
 }
 
-// UpdateContent handles menu selection and updates the display
-func UpdateContent(state *types.AppState, section string) {
-	log.Printf("Updating content for section: %s", section)
+// showSection renders a cached section, or its error in the status bar.
+// It reports whether anything was cached for the section.
+func showSection(state *types.AppState, section string) bool {
+	key := cacheKey(section, state.Query)
 
-	// Check if data is already loaded
 	state.CacheMutex.RLock()
-	data, exists := state.DataCache[section]
+	data, hasData := state.DataCache[key]
+	err, hasErr := state.ErrorCache[key]
 	state.CacheMutex.RUnlock()
 
-	if exists {
-		// Use already loaded data
-		log.Printf("Using loaded data for %s", section)
-		state.Header.SetText("[green]AWS Cost Explorer")
-		ui.PopulateTable(state.MainTable, data)
-	} else {
-		// Data not loaded yet, show loading message and fetch asynchronously
-		log.Printf("Data for %s not ready yet, fetching asynchronously", section)
-
-		loadingData := types.CostData{
-			Title: fmt.Sprintf("%s - Loading...", section),
-			Rows: [][]string{
-				{"Status", "Message"},
-				{"Loading", "Data is being fetched..."},
-			},
+	view, exists := registry.Lookup(section)
+	if !exists {
+		return false
+	}
+	updateFooter(state, view)
+
+	switch {
+	case hasData:
+		view.Render(state.MainTable, data)
+		updateDetailPane(state)
+		if data.Notice != "" {
+			state.StatusBar.SetText(fmt.Sprintf("[yellow]⚠ %s[-]", data.Notice))
+		} else {
+			state.StatusBar.SetText(fmt.Sprintf("[green]✓[-] %s loaded", section))
+		}
+		return true
+	case hasErr:
+		ui.PopulateTable(state.MainTable, types.CostData{Title: section})
+		ui.ShowDetailPane(state, nil)
+		state.StatusBar.SetText(errorStatus(section, err))
+		return true
+	}
+	return false
+}
+
+// updateFooter shows the base help text plus the view's extra keys
+func updateFooter(state *types.AppState, view View) {
+	var hints []string
+	if _, ok := view.(selectableView); ok {
+		hints = append(hints, "Enter to drill down")
+	}
+	for _, binding := range view.KeyBindings() {
+		hints = append(hints, fmt.Sprintf("'%c' %s", binding.Key, binding.Help))
+	}
+	ui.SetFooterHints(state.Footer, hints)
+}
+
+// setHeader shows a message followed by the active query settings
+func setHeader(state *types.AppState, message string) {
+	dateRange := state.Query.Range.String()
+	if state.Query.Range.IsZero() {
+		dateRange = fmt.Sprintf("%d months", state.Query.Months)
+	}
+	header := fmt.Sprintf("%s[-] | Profile: [::b]%s[::-] | Metric: [::b]%s[::-] | Range: [::b]%s[::-]",
+		message, tview.Escape(currentProfile(state)), aws.MetricLabel(state.Query.Metric), dateRange)
+	if !state.Query.Filter.IsZero() {
+		header += fmt.Sprintf(" | Filter: [::b]%s[::-]", tview.Escape(state.Query.Filter.String()))
+	}
+	if currency := ui.ReportingCurrency(); currency != "" {
+		header += fmt.Sprintf(" | Currency: [::b]%s[::-]", currency)
+	}
+	if len(state.Query.ExcludedRecordTypes) > 0 {
+		header += fmt.Sprintf(" | Excluding: [::b]%s[::-]", strings.Join(state.Query.ExcludedRecordTypes, ", "))
+	}
+
+	if badge := anomalyBadge(state); badge != "" {
+		header += " | " + badge
+	}
+
+	// Views drilled into a row show the path to it
+	if view, exists := registry.Lookup(state.CurrentSection); exists {
+		if crumbs, ok := view.(breadcrumbView); ok && crumbs.Breadcrumbs() != "" {
+			header += fmt.Sprintf(" | [::b]%s[::-]", tview.Escape(crumbs.Breadcrumbs()))
+		}
+	}
+	state.Header.SetText(header)
+}
+
+// errorStatus formats a fetch error for the status bar
+func errorStatus(section string, err error) string {
+	var fetchErr *aws.FetchError
+	if errors.As(err, &fetchErr) {
+		message := fmt.Sprintf("[red]✗ %s: %s[-]", section, fetchErr.Kind)
+		if hint := fetchErr.Kind.Hint(); hint != "" {
+			message += " - " + hint
 		}
+		return message + " | press 'r' to retry"
+	}
+	return fmt.Sprintf("[red]✗ %s: %v[-] | press 'r' to retry", section, err)
+}
 
-		state.Header.SetText(fmt.Sprintf("[yellow]%s data loading...[-]", section))
-		ui.PopulateTable(state.MainTable, loadingData)
+// UpdateContent handles menu selection and updates the display
+func UpdateContent(state *types.AppState, section string) {
+	log.Printf("Updating content for section: %s", section)
+	state.CurrentSection = section
 
-		// Fetch data asynchronously to avoid blocking the UI
-		go func(sectionName string) {
-			log.Printf("Fetching %s data asynchronously...", sectionName)
-			var data types.CostData
-
-			switch sectionName {
-			case "Dashboard":
-				data = aws.GetDashboardData(state.Client)
-			case "By Service":
-				data = aws.GetServiceData(state.Client)
-			case "By Region":
-				data = aws.GetRegionData(state.Client)
-			case "By Usage Type":
-				data = aws.GetUsageTypeData(state.Client)
+	// Use already loaded data or error if present
+	if showSection(state, section) {
+		log.Printf("Using loaded data for %s", section)
+		setHeader(state, "[green]AWS Cost Explorer")
+		return
+	}
+
+	// Data not loaded yet, show loading message and fetch asynchronously
+	log.Printf("Data for %s not ready yet, fetching asynchronously", section)
+	fetchAsync(state, section)
+}
+
+// RetrySection drops the cached result for the current section and fetches it again
+func RetrySection(state *types.AppState) {
+	section := state.CurrentSection
+	log.Printf("Retrying %s", section)
+
+	key := cacheKey(section, state.Query)
+	state.CacheMutex.Lock()
+	delete(state.DataCache, key)
+	delete(state.ErrorCache, key)
+	state.CacheMutex.Unlock()
+
+	fetchAsync(state, section)
+}
+
+// showLoading shows a loading message for a section
+func showLoading(state *types.AppState, section string) {
+	loadingData := types.CostData{
+		Title:   fmt.Sprintf("%s - Loading...", section),
+		Columns: []types.Column{{Title: "Status"}, {Title: "Message"}},
+		Rows: [][]types.Cell{
+			{types.TextCell("Loading"), types.TextCell("Data is being fetched...")},
+		},
+	}
+
+	setHeader(state, fmt.Sprintf("[yellow]%s data loading...[-]", section))
+	state.StatusBar.SetText(fmt.Sprintf("[yellow]Fetching %s...[-]", section))
+	ui.PopulateTable(state.MainTable, loadingData)
+	ui.ShowDetailPane(state, nil)
+}
+
+// fetchAsync shows a loading message and fetches a section in the background
+func fetchAsync(state *types.AppState, section string) {
+	showLoading(state, section)
+
+	// Fetch data asynchronously to avoid blocking the UI
+	q := state.Query
+	key := cacheKey(section, q)
+	if !startFetch(state, section, q) {
+		// Already being fetched; that fetch shows the section when it finishes
+		return
+	}
+	go func(sectionName string) {
+		loadSection(state, sectionName, q)
+
+		// Update UI on main thread
+		state.App.QueueUpdateDraw(func() {
+			// Only update if user is still on the same section and settings
+			if state.CurrentSection != sectionName || cacheKey(sectionName, state.Query) != key {
+				return
 			}
+			setHeader(state, "[green]AWS Cost Explorer")
+			showSection(state, sectionName)
+			log.Printf("Updated UI with %s data", sectionName)
+		})
+	}(section)
+}
 
-			// Store data in cache
-			state.CacheMutex.Lock()
-			state.DataCache[sectionName] = data
-			state.CacheMutex.Unlock()
+// SwitchMetric selects the next cost metric and reloads every view for it.
+// Views already fetched with that metric are served from the cache.
+func SwitchMetric(state *types.AppState) {
+	state.Query.Metric = aws.NextMetric(state.Query.Metric)
+	log.Printf("Switched metric to %s", state.Query.Metric)
+	refreshQuery(state)
+}
 
-			// Update UI on main thread
-			state.App.QueueUpdateDraw(func() {
-				// Only update if user is still on the same section
-				state.CacheMutex.RLock()
-				currentData, exists := state.DataCache[sectionName]
-				state.CacheMutex.RUnlock()
-
-				if exists {
-					state.Header.SetText("[green]AWS Cost Explorer")
-					ui.PopulateTable(state.MainTable, currentData)
-					log.Printf("Updated UI with %s data", sectionName)
-				}
-			})
-		}(section)
+// ToggleCurrency switches amounts between the configured reporting currency
+// and the currency they were billed in, redrawing without refetching
+func ToggleCurrency(state *types.AppState) {
+	converted, configured := ui.ToggleReportingCurrency()
+	if !configured {
+		state.StatusBar.SetText("[yellow]No reporting currency; set currency.reporting and currency.rates in the config file[-]")
+		return
+	}
+	log.Printf("Reporting currency conversion set to %t", converted)
+
+	showSection(state, state.CurrentSection)
+	setHeader(state, "[green]AWS Cost Explorer")
+}
+
+// AdjustHistory changes how many months of history monthly views show when no
+// date range is set
+func AdjustHistory(state *types.AppState, delta int) {
+	if !state.Query.Range.IsZero() {
+		state.StatusBar.SetText("[yellow]A date range is set; reset it with 'd' to use the month history[-]")
+		return
+	}
+
+	months := state.Query.Months + delta
+	if months < 1 || months > aws.MaxHistoryMonths {
+		return
+	}
+	state.Query.Months = months
+	log.Printf("History set to %d months", months)
+	refreshQuery(state)
+}
+
+// refreshQuery shows the current section for the active query and preloads
+// the remaining views in the background
+func refreshQuery(state *types.AppState) {
+	if showSection(state, state.CurrentSection) {
+		setHeader(state, "[green]AWS Cost Explorer")
+	} else {
+		showLoading(state, state.CurrentSection)
 	}
+	go LoadAllData(state, state.Query)
 }
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// dateRangePage is the page name of the date range picker
+const dateRangePage = "daterange"
+
+// OpenDateRangePicker shows the modal used to choose a preset or custom range
+func OpenDateRangePicker(state *types.AppState) {
+	previousFocus := state.App.GetFocus()
+	closePicker := func() {
+		state.Pages.RemovePage(dateRangePage)
+		state.App.SetFocus(previousFocus)
+	}
+
+	presets := make([]string, len(aws.RangePresets))
+	for i, preset := range aws.RangePresets {
+		presets[i] = preset.Label
+	}
+
+	// Prefill the dates with the active range, or the current month
+	current := state.Query.Range
+	if current.IsZero() {
+		current, _ = aws.PresetRange("mtd", time.Now())
+		current.Label = ""
+	}
+	from := current.Start.Format("2006-01-02")
+	to := current.End.AddDate(0, 0, -1).Format("2006-01-02")
+
+	form := ui.CreateDateRangeForm(presets, state.Query.Range.Label, from, to,
+		func(preset, from, to string) {
+			var r types.DateRange
+			var err error
+			if preset != "" {
+				r, err = aws.PresetRange(preset, time.Now())
+			} else {
+				r, err = aws.ParseRange(from, to)
+			}
+			if err != nil {
+				state.StatusBar.SetText("[red]✗ " + err.Error() + "[-]")
+				return
+			}
+			closePicker()
+			SetDateRange(state, r)
+		},
+		func() {
+			closePicker()
+			SetDateRange(state, types.DateRange{})
+		},
+		closePicker,
+	)
+
+	state.Pages.AddPage(dateRangePage, ui.CenteredModal(form, 48, 11), true, true)
+	state.App.SetFocus(form)
+}
+
+// SetDateRange applies a range to every view; a zero range restores each
+// view's default period
+func SetDateRange(state *types.AppState, r types.DateRange) {
+	state.Query.Range = r
+	log.Printf("Date range set to %s", r)
+	refreshQuery(state)
+}
@@ -0,0 +1,167 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+
+	"github.com/rivo/tview"
+)
+
+// detailedView is implemented by views whose rows have details, shown in the
+// detail pane for the selected table row
+type detailedView interface {
+	Details(row int) []types.DetailField
+}
+
+// detailTableView is a view of records with details per row, such as
+// recommendations. 's' cycles through the columns it sorts by, which only
+// redraws the fetched data.
+type detailTableView struct {
+	*tableView
+	sortColumns []string // Titles of the columns to sort by; the first is the default
+	sortIndex   atomic.Int64
+
+	mu    sync.Mutex
+	shown types.CostData // The data as sorted and drawn
+}
+
+// newDetailTableView creates a view sorted by the first of sortColumns
+func newDetailTableView(name string, sortColumns []string, fetch func(types.CostExplorerAPI, types.Query) (types.CostData, error), keys ...KeyBinding) *detailTableView {
+	v := &detailTableView{sortColumns: sortColumns}
+	keys = append(keys, KeyBinding{Key: 's', Help: "sort", Action: v.nextSort})
+	v.tableView = newTableView(name, fetch, keys...)
+	return v
+}
+
+// sortColumn returns the title of the column sorted by
+func (v *detailTableView) sortColumn() string {
+	return v.sortColumns[v.sortIndex.Load()]
+}
+
+// Render draws the data sorted by the selected column
+func (v *detailTableView) Render(table *tview.Table, data types.CostData) {
+	sorted := sortData(data, v.sortColumn())
+
+	v.mu.Lock()
+	v.shown = sorted
+	v.mu.Unlock()
+
+	ui.PopulateTable(table, sorted)
+}
+
+// Details returns the details of a table row as drawn
+func (v *detailTableView) Details(row int) []types.DetailField {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if row < 1 || row > len(v.shown.Details) {
+		return nil
+	}
+	return v.shown.Details[row-1]
+}
+
+// RowKey returns the raw values behind a table row as drawn
+func (v *detailTableView) RowKey(row int) []string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if row < 1 || row > len(v.shown.RowKeys) {
+		return nil
+	}
+	return v.shown.RowKeys[row-1]
+}
+
+// nextSort sorts by the next column and redraws the view
+func (v *detailTableView) nextSort(state *types.AppState) {
+	v.sortIndex.Store((v.sortIndex.Load() + 1) % int64(len(v.sortColumns)))
+	log.Printf("%s sorted by %s", v.Name(), v.sortColumn())
+
+	if showSection(state, v.Name()) {
+		state.MainTable.Select(1, 0)
+		state.StatusBar.SetText(fmt.Sprintf("[green]✓[-] Sorted by %s", v.sortColumn()))
+	}
+}
+
+// sortData returns a copy of data with its rows, and their keys and details,
+// sorted by the titled column: largest first for numbers, alphabetically for
+// text. Blank cells go last.
+func sortData(data types.CostData, title string) types.CostData {
+	column := -1
+	for i, c := range data.Columns {
+		if c.Title == title {
+			column = i
+		}
+	}
+	if column < 0 {
+		return data
+	}
+
+	order := make([]int, len(data.Rows))
+	for i := range order {
+		order[i] = i
+	}
+	cell := func(row int) types.Cell {
+		if column < len(data.Rows[row]) {
+			return data.Rows[row][column]
+		}
+		return types.BlankCell()
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := cell(order[i]), cell(order[j])
+		if a.Blank != b.Blank {
+			return b.Blank
+		}
+		if data.Columns[column].Kind == types.KindDimension {
+			return a.Text < b.Text
+		}
+		return a.Value > b.Value
+	})
+
+	sorted := data
+	sorted.Title = fmt.Sprintf("%s (by %s)", data.Title, title)
+	sorted.Rows = make([][]types.Cell, len(order))
+	if data.RowKeys != nil {
+		sorted.RowKeys = make([][]string, len(order))
+	}
+	if data.Details != nil {
+		sorted.Details = make([][]types.DetailField, len(order))
+	}
+	for i, row := range order {
+		sorted.Rows[i] = data.Rows[row]
+		if row < len(data.RowKeys) {
+			sorted.RowKeys[i] = data.RowKeys[row]
+		}
+		if row < len(data.Details) {
+			sorted.Details[i] = data.Details[row]
+		}
+	}
+	return sorted
+}
+
+// updateDetailPane shows the details of the selected row when the current
+// view has them, and hides the pane otherwise
+func updateDetailPane(state *types.AppState) {
+	view, exists := registry.Lookup(state.CurrentSection)
+	if !exists {
+		ui.ShowDetailPane(state, nil)
+		return
+	}
+	detailed, ok := view.(detailedView)
+	if _, cached := cachedData(state, state.CurrentSection); !ok || !cached {
+		ui.ShowDetailPane(state, nil)
+		return
+	}
+
+	// Until a row is selected the first one is described
+	row, _ := state.MainTable.GetSelection()
+	if row < 1 {
+		row = 1
+	}
+	ui.ShowDetailPane(state, detailed.Details(row))
+}
@@ -0,0 +1,164 @@
+package app
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+
+	"github.com/rivo/tview"
+)
+
+// selectableView is implemented by views that act on a table row chosen with Enter
+type selectableView interface {
+	Select(state *types.AppState, row int)
+}
+
+// breadcrumbView is implemented by views that show a path in the header
+type breadcrumbView interface {
+	Breadcrumbs() string
+}
+
+// drillLevels are the dimensions shown below a service, region or account row
+var drillLevels = []string{"USAGE_TYPE", "OPERATION"}
+
+// drillStep is one level of a drill-down: the filter chosen and the table
+// row it was chosen from, restored when going back
+type drillStep struct {
+	Filter types.DimensionFilter
+	Row    int
+}
+
+// drillView is a view whose rows can be chosen with Enter to show the costs
+// of that row grouped by the next dimension in levels. 'b' or Backspace goes
+// back up to the row that was chosen.
+type drillView struct {
+	*tableView
+	base        func(types.CostExplorerAPI, types.Query) (types.CostData, error)
+	dimension   string   // Dimension of the rows at the top level
+	levels      []string // Dimension shown at each depth below the top level
+	labelColumn int      // Column naming a top-level row in breadcrumbs
+
+	mu         sync.Mutex
+	path       []drillStep
+	restoreRow int // Row to select once the level gone back to is drawn
+}
+
+// newDrillView creates a drillable view showing fetch at the top level
+func newDrillView(name, dimension string, labelColumn int, levels []string, fetch func(types.CostExplorerAPI, types.Query) (types.CostData, error), keys ...KeyBinding) *drillView {
+	v := &drillView{base: fetch, dimension: dimension, levels: levels, labelColumn: labelColumn}
+	keys = append(keys, KeyBinding{Key: 'b', Help: "back", Action: v.Back})
+	v.tableView = newTableView(name, v.fetchLevel, keys...)
+	return v
+}
+
+// filters returns the filters of the current path
+func (v *drillView) filters() []types.DimensionFilter {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	filters := make([]types.DimensionFilter, len(v.path))
+	for i, step := range v.path {
+		filters[i] = step.Filter
+	}
+	return filters
+}
+
+// Variant identifies the current path
+func (v *drillView) Variant() string {
+	var parts []string
+	for _, filter := range v.filters() {
+		parts = append(parts, filter.Dimension+"="+strings.Join(filter.Values, ","))
+	}
+	return strings.Join(parts, "/")
+}
+
+// Breadcrumbs returns the view name followed by the rows drilled into, or an
+// empty string at the top level
+func (v *drillView) Breadcrumbs() string {
+	filters := v.filters()
+	if len(filters) == 0 {
+		return ""
+	}
+
+	crumbs := []string{v.Name()}
+	for _, filter := range filters {
+		crumbs = append(crumbs, filter.Label)
+	}
+	return strings.Join(crumbs, " › ")
+}
+
+// fetchLevel loads the top level, or the costs within the current path
+func (v *drillView) fetchLevel(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	filters := v.filters()
+	if len(filters) == 0 {
+		return v.base(client, q)
+	}
+	return aws.GetDrillData(client, q, filters, v.levels[len(filters)-1])
+}
+
+// Render draws the data and selects the row left when going back
+func (v *drillView) Render(table *tview.Table, data types.CostData) {
+	ui.PopulateTable(table, data)
+
+	v.mu.Lock()
+	row := v.restoreRow
+	v.restoreRow = 0
+	v.mu.Unlock()
+
+	if row > 0 && row < table.GetRowCount() {
+		table.Select(row, 0)
+	}
+}
+
+// Select drills into the chosen table row
+func (v *drillView) Select(state *types.AppState, row int) {
+	data, exists := cachedData(state, v.Name())
+	if !exists || row < 1 || row > len(data.Rows) {
+		return
+	}
+	cells := data.Rows[row-1]
+
+	v.mu.Lock()
+	depth := len(v.path)
+	if depth >= len(v.levels) {
+		v.mu.Unlock()
+		state.StatusBar.SetText("[yellow]No further drill-down from here; press 'b' to go back[-]")
+		return
+	}
+
+	// Rows with normalized labels carry the raw values to filter on
+	name := strings.TrimSuffix(cells[0].Text, aws.AliasMarker)
+	filter := types.DimensionFilter{Dimension: v.dimension, Values: []string{name}, Label: name}
+	if row-1 < len(data.RowKeys) && len(data.RowKeys[row-1]) > 0 {
+		filter.Values = data.RowKeys[row-1]
+	}
+	if depth > 0 {
+		filter.Dimension = v.levels[depth-1]
+	} else if label := strings.TrimSuffix(cells[v.labelColumn].Text, aws.AliasMarker); label != "" {
+		filter.Label = label
+	}
+	v.path = append(v.path, drillStep{Filter: filter, Row: row})
+	v.mu.Unlock()
+
+	log.Printf("Drilling into %s=%s", filter.Dimension, strings.Join(filter.Values, ","))
+	UpdateContent(state, v.Name())
+}
+
+// Back returns to the previous level and selects the row drilled into
+func (v *drillView) Back(state *types.AppState) {
+	v.mu.Lock()
+	if len(v.path) == 0 {
+		v.mu.Unlock()
+		return
+	}
+	step := v.path[len(v.path)-1]
+	v.path = v.path[:len(v.path)-1]
+	v.restoreRow = step.Row
+	v.mu.Unlock()
+
+	UpdateContent(state, v.Name())
+}
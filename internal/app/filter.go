@@ -0,0 +1,62 @@
+package app
+
+import (
+	"log"
+	"sync"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// filterPage is the page name of the filter builder
+const filterPage = "filter"
+
+// OpenFilterBuilder shows the panel used to build the filter applied to every
+// view. Dimension values for autocompletion load in the background.
+func OpenFilterBuilder(state *types.AppState) {
+	previousFocus := state.App.GetFocus()
+	closeBuilder := func() {
+		state.Pages.RemovePage(filterPage)
+		state.App.SetFocus(previousFocus)
+	}
+
+	var mu sync.Mutex
+	known := make(map[string][]string)
+	q := state.Query
+	for _, dimension := range aws.FilterDimensions {
+		go func(dimension string) {
+			values, err := aws.ListDimensionValues(queryClient(state, q), q, dimension)
+			if err != nil {
+				log.Printf("Failed to load %s values: %v", dimension, err)
+				return
+			}
+			mu.Lock()
+			known[dimension] = values
+			mu.Unlock()
+		}(dimension)
+	}
+	complete := func(dimension string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return known[dimension]
+	}
+
+	panel, form := ui.CreateFilterPanel(state.Query.Filter, aws.FilterDimensions, complete,
+		func(filter types.Filter) {
+			closeBuilder()
+			SetFilter(state, filter)
+		},
+		closeBuilder,
+	)
+
+	state.Pages.AddPage(filterPage, ui.CenteredModal(panel, 72, 26), true, true)
+	state.App.SetFocus(form)
+}
+
+// SetFilter applies a filter to every view; a zero filter removes it
+func SetFilter(state *types.AppState, filter types.Filter) {
+	state.Query.Filter = filter
+	log.Printf("Filter set to %s", filter)
+	refreshQuery(state)
+}
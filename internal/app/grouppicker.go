@@ -0,0 +1,52 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// groupPickerPage is the page name of the tag key and cost category picker
+const groupPickerPage = "grouppicker"
+
+// OpenGroupPicker loads the keys a view can group by and shows a modal to
+// choose one
+func OpenGroupPicker(state *types.AppState, view *groupPickerView) {
+	state.StatusBar.SetText(fmt.Sprintf("[yellow]Fetching %ss...[-]", view.label))
+	q := state.Query
+
+	go func() {
+		keys, err := view.list(queryClient(state, q), q)
+
+		state.App.QueueUpdateDraw(func() {
+			if err != nil {
+				state.StatusBar.SetText(errorStatus(view.Name(), err))
+				return
+			}
+			if len(keys) == 0 {
+				state.StatusBar.SetText("[yellow]⚠ " + view.emptyHint + "[-]")
+				return
+			}
+			state.StatusBar.SetText(fmt.Sprintf("%d %ss", len(keys), view.label))
+
+			previousFocus := state.App.GetFocus()
+			closePicker := func() {
+				state.Pages.RemovePage(groupPickerPage)
+				state.App.SetFocus(previousFocus)
+			}
+
+			title := view.keyTitle()
+			list := ui.CreatePickerList(title, keys, view.Key(), func(key string) {
+				closePicker()
+				view.SetKey(key)
+				log.Printf("%s set to %s", title, key)
+				UpdateContent(state, view.Name())
+			}, closePicker)
+
+			state.Pages.AddPage(groupPickerPage, ui.CenteredModal(list, 40, min(len(keys)+2, 16)), true, true)
+			state.App.SetFocus(list)
+		})
+	}()
+}
@@ -8,25 +8,74 @@ import (
 	"github.com/gdamore/tcell/v2"
 )
 
-// GetMenuItems returns the list of menu items
+// GetMenuItems returns the list of menu items in registry order
 func GetMenuItems() []string {
-	return []string{
-		"Dashboard",
-		"By Service",
-		"By Usage Type",
-		"By Region",
+	return registry.Names()
+}
+
+// handleViewKey runs the current view's binding for a key, if it has one
+func handleViewKey(state *types.AppState, key rune) bool {
+	view, exists := registry.Lookup(state.CurrentSection)
+	if !exists {
+		return false
+	}
+
+	for _, binding := range view.KeyBindings() {
+		if binding.Key == key {
+			binding.Action(state)
+			return true
+		}
 	}
+	return false
 }
 
 // SetupKeyBindings configures keyboard input handling
 func SetupKeyBindings(state *types.AppState, updateContentFunc func(*types.AppState, string)) {
 	state.App.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Leave keys to an open modal such as the date range picker
+		if name, _ := state.Pages.GetFrontPage(); name != mainPage {
+			return event
+		}
+
 		currentFocus := state.App.GetFocus()
 
+		// View-specific keys take precedence while the table has focus
+		if currentFocus == state.MainTable && event.Key() == tcell.KeyRune && handleViewKey(state, event.Rune()) {
+			return nil
+		}
+
 		switch event.Rune() {
 		case 'q':
 			state.App.Stop()
 			return nil
+		case 'r':
+			// Retry the current section, e.g. after a fetch error
+			RetrySection(state)
+			return nil
+		case 'm':
+			// Cycle through the cost metrics
+			SwitchMetric(state)
+			return nil
+		case 'd':
+			// Choose the date range for every view
+			OpenDateRangePicker(state)
+			return nil
+		case 'f':
+			// Build the filter applied to every view
+			OpenFilterBuilder(state)
+			return nil
+		case 'u':
+			// Switch between the billed and reporting currency
+			ToggleCurrency(state)
+			return nil
+		case 'P':
+			// Switch to another AWS profile
+			OpenProfilePicker(state)
+			return nil
+		case 'x':
+			// Choose the record types counted in the service, region and account views
+			OpenRecordTypePicker(state)
+			return nil
 		case 'j':
 			// Move down in menu or table
 			if currentFocus == state.Menu {
@@ -117,6 +166,25 @@ func SetupKeyBindings(state *types.AppState, updateContentFunc func(*types.AppSt
 					state.App.SetFocus(state.MainTable)
 				}
 				return nil
+			} else if currentFocus == state.MainTable {
+				// Act on the selected row in views that support it
+				if view, exists := registry.Lookup(state.CurrentSection); exists {
+					if selectable, ok := view.(selectableView); ok {
+						row, _ := state.MainTable.GetSelection()
+						selectable.Select(state, row)
+						return nil
+					}
+				}
+			}
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			// Go back up a drill-down
+			if currentFocus == state.MainTable {
+				if view, exists := registry.Lookup(state.CurrentSection); exists {
+					if drill, ok := view.(*drillView); ok {
+						drill.Back(state)
+						return nil
+					}
+				}
 			}
 		case tcell.KeyPgDn:
 			// Page down in table
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"log"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// profilePage is the page name of the profile picker
+const profilePage = "profile"
+
+// OpenProfilePicker shows the modal listing the profiles in the shared AWS
+// config to switch to
+func OpenProfilePicker(state *types.AppState) {
+	profiles, err := aws.ListProfiles()
+	if err != nil {
+		state.StatusBar.SetText(fmt.Sprintf("[red]✗ Reading AWS profiles: %v[-]", err))
+		return
+	}
+	if len(profiles) == 0 {
+		state.StatusBar.SetText("[yellow]⚠ No profiles in the AWS config or credentials file[-]")
+		return
+	}
+
+	previousFocus := state.App.GetFocus()
+	closePicker := func() {
+		state.Pages.RemovePage(profilePage)
+		state.App.SetFocus(previousFocus)
+	}
+
+	list := ui.CreatePickerList("AWS Profile", profiles, currentProfile(state), func(profile string) {
+		closePicker()
+		SwitchProfile(state, profile)
+	}, closePicker)
+
+	state.Pages.AddPage(profilePage, ui.CenteredModal(list, 40, min(len(profiles), 16)+2), true, true)
+	state.App.SetFocus(list)
+}
+
+// SwitchProfile shows the data of another AWS profile, creating its client the
+// first time. Data already loaded for the profile is kept, so switching back
+// does not refetch it.
+func SwitchProfile(state *types.AppState, profile string) {
+	if profile == currentProfile(state) {
+		return
+	}
+	log.Printf("Switching to profile %s", profile)
+
+	profile = profileKey(profile)
+
+	state.CacheMutex.RLock()
+	client, exists := state.Clients[profile]
+	state.CacheMutex.RUnlock()
+
+	if !exists {
+		var err error
+		client, err = state.NewClient(profile)
+		if err != nil {
+			state.StatusBar.SetText(fmt.Sprintf("[red]✗ Profile %s: %v[-]", currentProfileName(profile), err))
+			return
+		}
+		state.CacheMutex.Lock()
+		state.Clients[profile] = client
+		state.CacheMutex.Unlock()
+	}
+
+	state.Client = client
+	state.Query.Profile = profile
+	refreshQuery(state)
+}
+
+// profileKey returns the profile a query and its client are stored under.
+// The environment's profile is the one used without naming any, so both
+// share a client and cached data.
+func profileKey(profile string) string {
+	if profile == aws.CurrentProfile() {
+		return ""
+	}
+	return profile
+}
+
+// currentProfile names the profile the current client uses
+func currentProfile(state *types.AppState) string {
+	return currentProfileName(state.Query.Profile)
+}
+
+// currentProfileName names a query's profile, which is empty for the
+// environment's profile
+func currentProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	return aws.CurrentProfile()
+}
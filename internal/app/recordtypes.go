@@ -0,0 +1,47 @@
+package app
+
+import (
+	"log"
+	"strings"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+)
+
+// recordTypePage is the page name of the record type picker
+const recordTypePage = "recordtypes"
+
+// OpenRecordTypePicker shows the modal used to choose which record types,
+// such as credits, refunds and taxes, count toward the service, region and
+// account views
+func OpenRecordTypePicker(state *types.AppState) {
+	previousFocus := state.App.GetFocus()
+	closePicker := func() {
+		state.Pages.RemovePage(recordTypePage)
+		state.App.SetFocus(previousFocus)
+	}
+
+	form := ui.CreateRecordTypeForm(aws.RecordTypes, state.Query.ExcludedRecordTypes,
+		func(excluded []string) {
+			closePicker()
+			SetExcludedRecordTypes(state, excluded)
+		},
+		func() {
+			closePicker()
+			SetExcludedRecordTypes(state, nil)
+		},
+		closePicker,
+	)
+
+	state.Pages.AddPage(recordTypePage, ui.CenteredModal(form, 44, len(aws.RecordTypes)+5), true, true)
+	state.App.SetFocus(form)
+}
+
+// SetExcludedRecordTypes leaves record types out of the service, region and
+// account views; none restores every record type
+func SetExcludedRecordTypes(state *types.AppState, excluded []string) {
+	state.Query.ExcludedRecordTypes = excluded
+	log.Printf("Excluded record types set to [%s]", strings.Join(excluded, ", "))
+	refreshQuery(state)
+}
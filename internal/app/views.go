@@ -0,0 +1,443 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"cost-explorer/internal/aws"
+	"cost-explorer/internal/types"
+	"cost-explorer/internal/ui"
+
+	"github.com/rivo/tview"
+)
+
+// KeyBinding is an extra key handled while a view's table has focus
+type KeyBinding struct {
+	Key    rune
+	Help   string
+	Action func(state *types.AppState)
+}
+
+// View is one menu section: where its data comes from, how it is drawn and
+// which extra keys it handles
+type View interface {
+	Name() string
+	Fetch(client types.CostExplorerAPI, q types.Query) (types.CostData, error)
+	Render(table *tview.Table, data types.CostData)
+	KeyBindings() []KeyBinding
+	// QueryKey identifies the settings of a query the view's data depends on
+	QueryKey(q types.Query) string
+}
+
+// variantView is implemented by views with their own settings, such as the
+// hourly window. The variant is part of the cache key so that each setting
+// is cached separately.
+type variantView interface {
+	Variant() string
+}
+
+// tableView is a View that draws its data with ui.PopulateTable
+type tableView struct {
+	name  string
+	fetch func(types.CostExplorerAPI, types.Query) (types.CostData, error)
+	keys  []KeyBinding
+	scope func(types.Query) string // Settings the data depends on; nil for all of them
+}
+
+// newTableView creates a table-backed view
+func newTableView(name string, fetch func(types.CostExplorerAPI, types.Query) (types.CostData, error), keys ...KeyBinding) *tableView {
+	return &tableView{name: name, fetch: fetch, keys: keys}
+}
+
+// Name returns the menu label of the view
+func (v *tableView) Name() string {
+	return v.name
+}
+
+// Fetch loads the view's data
+func (v *tableView) Fetch(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	return v.fetch(client, q)
+}
+
+// Render draws the view's data into the table
+func (v *tableView) Render(table *tview.Table, data types.CostData) {
+	ui.PopulateTable(table, data)
+}
+
+// KeyBindings returns the view's extra keys
+func (v *tableView) KeyBindings() []KeyBinding {
+	return v.keys
+}
+
+// QueryKey identifies the settings the view's data depends on, every one of
+// the query's unless the view is scoped
+func (v *tableView) QueryKey(q types.Query) string {
+	if v.scope == nil {
+		return q.Key()
+	}
+	return v.scope(q)
+}
+
+// profileScope is the scope of views that ignore every setting but the
+// profile, such as recommendations. Whether a filter is set is included, as
+// their notice says that it does not apply.
+func profileScope(q types.Query) string {
+	return fmt.Sprintf("%s|filtered=%t", q.Profile, !q.Filter.IsZero())
+}
+
+// historyScope is the scope of views that depend on the profile and the
+// date range or months of history alone, such as anomalies. Like
+// profileScope it includes whether a filter is set for their notice.
+func historyScope(q types.Query) string {
+	return fmt.Sprintf("%s|%s|%d|filtered=%t", q.Profile, q.Range.Key(), q.Months, !q.Filter.IsZero())
+}
+
+// hourlyView is the Hourly view; 'w' cycles through aws.HourlyWindows
+type hourlyView struct {
+	*tableView
+	hours atomic.Int64
+}
+
+// newHourlyView creates the Hourly view showing the shortest window
+func newHourlyView() *hourlyView {
+	v := &hourlyView{}
+	v.hours.Store(int64(aws.HourlyWindows[0]))
+	v.tableView = newTableView("Hourly", func(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+		return aws.GetHourlyData(client, q, int(v.hours.Load()))
+	}, KeyBinding{Key: 'w', Help: "change window", Action: v.nextWindow})
+	return v
+}
+
+// Variant returns the selected window
+func (v *hourlyView) Variant() string {
+	return fmt.Sprintf("%dh", v.hours.Load())
+}
+
+// nextWindow selects the next hourly window and shows it
+func (v *hourlyView) nextWindow(state *types.AppState) {
+	hours := int(v.hours.Load())
+	next := aws.HourlyWindows[0]
+	for i, window := range aws.HourlyWindows {
+		if window == hours && i+1 < len(aws.HourlyWindows) {
+			next = aws.HourlyWindows[i+1]
+		}
+	}
+	v.hours.Store(int64(next))
+	log.Printf("Hourly window set to %d hours", next)
+	UpdateContent(state, v.Name())
+}
+
+// forecastView is the Forecast view; 'g' switches between the monthly and
+// daily forecast and '+'/'-' change the months covered
+type forecastView struct {
+	*tableView
+	months atomic.Int64
+	daily  atomic.Bool
+}
+
+// newForecastView creates the Forecast view with the default monthly horizon
+func newForecastView() *forecastView {
+	v := &forecastView{}
+	v.months.Store(aws.DefaultForecastMonths)
+	v.tableView = newTableView("Forecast", func(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+		return aws.GetForecastData(client, q, int(v.months.Load()), v.daily.Load())
+	},
+		KeyBinding{Key: 'g', Help: "monthly/daily", Action: v.toggleDaily},
+		KeyBinding{Key: '+', Help: "more months", Action: func(state *types.AppState) { v.adjustMonths(state, 1) }},
+		KeyBinding{Key: '-', Help: "fewer months", Action: func(state *types.AppState) { v.adjustMonths(state, -1) }},
+	)
+	return v
+}
+
+// Variant returns the selected horizon
+func (v *forecastView) Variant() string {
+	if v.daily.Load() {
+		return "daily"
+	}
+	return fmt.Sprintf("%dm", v.months.Load())
+}
+
+// toggleDaily switches between the monthly and the daily forecast
+func (v *forecastView) toggleDaily(state *types.AppState) {
+	v.daily.Store(!v.daily.Load())
+	log.Printf("Forecast set to %s", v.Variant())
+	UpdateContent(state, v.Name())
+}
+
+// adjustMonths changes the months covered by the monthly forecast
+func (v *forecastView) adjustMonths(state *types.AppState, delta int) {
+	if v.daily.Load() {
+		state.StatusBar.SetText("[yellow]The daily forecast always covers 30 days; press 'g' for the monthly forecast[-]")
+		return
+	}
+
+	months := v.months.Load() + int64(delta)
+	if months < 1 || months > aws.MaxForecastMonths {
+		return
+	}
+	v.months.Store(months)
+	log.Printf("Forecast set to %s", v.Variant())
+	UpdateContent(state, v.Name())
+}
+
+// modeView shows one of several modes of its data, cycled with 'v'
+type modeView struct {
+	*tableView
+	modes []string
+	mode  atomic.Int64
+}
+
+// newModeView creates a view showing the first of modes. Its data, commitment
+// utilization and coverage, ignores the metric, filter and record types.
+func newModeView(name string, modes []string, fetch func(types.CostExplorerAPI, types.Query, string) (types.CostData, error)) *modeView {
+	v := &modeView{modes: modes}
+	v.tableView = newTableView(name, func(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+		return fetch(client, q, v.Variant())
+	}, KeyBinding{Key: 'v', Help: strings.Join(modes, "/"), Action: v.nextMode})
+	v.scope = historyScope
+	return v
+}
+
+// Variant returns the selected mode
+func (v *modeView) Variant() string {
+	return v.modes[v.mode.Load()]
+}
+
+// nextMode selects the next mode and shows it
+func (v *modeView) nextMode(state *types.AppState) {
+	v.mode.Store((v.mode.Load() + 1) % int64(len(v.modes)))
+	log.Printf("%s set to %s", v.Name(), v.Variant())
+	UpdateContent(state, v.Name())
+}
+
+// purchaseView shows the commitments Cost Explorer recommends buying: 'v'
+// switches between Savings Plans and Reserved Instances, and 'y', 'p' and
+// 'w' cycle the term, payment option and lookback period
+type purchaseView struct {
+	*detailTableView
+	kind     atomic.Int64
+	term     atomic.Int64
+	payment  atomic.Int64
+	lookback atomic.Int64
+}
+
+// newPurchaseView creates the view with the console's default options
+func newPurchaseView() *purchaseView {
+	v := &purchaseView{}
+	v.lookback.Store(1) // 30 days
+	v.detailTableView = newDetailTableView("Purchase Advice", []string{aws.PurchaseSavingsColumn, aws.PurchaseUpfrontColumn},
+		func(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+			return aws.GetPurchaseRecommendationData(client, q, v.options())
+		},
+		KeyBinding{Key: 'v', Help: strings.Join(aws.PurchaseKinds, "/"), Action: func(state *types.AppState) {
+			v.cycle(state, &v.kind, len(aws.PurchaseKinds))
+		}},
+		KeyBinding{Key: 'y', Help: "term", Action: func(state *types.AppState) {
+			v.cycle(state, &v.term, len(aws.PurchaseTerms))
+		}},
+		KeyBinding{Key: 'p', Help: "payment", Action: func(state *types.AppState) {
+			v.cycle(state, &v.payment, len(aws.PurchasePayments))
+		}},
+		KeyBinding{Key: 'w', Help: "lookback", Action: func(state *types.AppState) {
+			v.cycle(state, &v.lookback, len(aws.PurchaseLookbacks))
+		}},
+	)
+	v.scope = profileScope
+	return v
+}
+
+// options returns the selected commitment and options
+func (v *purchaseView) options() aws.PurchaseOptions {
+	return aws.PurchaseOptions{
+		Kind:     aws.PurchaseKinds[v.kind.Load()],
+		Term:     aws.PurchaseTerms[v.term.Load()],
+		Payment:  aws.PurchasePayments[v.payment.Load()],
+		Lookback: aws.PurchaseLookbacks[v.lookback.Load()],
+	}
+}
+
+// Variant returns the selected commitment and options
+func (v *purchaseView) Variant() string {
+	options := v.options()
+	return options.Kind + ": " + options.String()
+}
+
+// cycle selects the next value of a setting with count values and shows it
+func (v *purchaseView) cycle(state *types.AppState, setting *atomic.Int64, count int) {
+	setting.Store((setting.Load() + 1) % int64(count))
+	log.Printf("%s set to %s", v.Name(), v.Variant())
+	UpdateContent(state, v.Name())
+}
+
+// groupPickerView groups costs by a key chosen from a list loaded from Cost
+// Explorer, such as a tag key or a cost category. Until a key is chosen it
+// lists the available keys.
+type groupPickerView struct {
+	*tableView
+	label     string // What a key is, e.g. "tag key"
+	emptyHint string // Shown when there are no keys to choose from
+	list      func(types.CostExplorerAPI, types.Query) ([]string, error)
+	fetchKey  func(types.CostExplorerAPI, types.Query, string) (types.CostData, error)
+	key       atomic.Value
+}
+
+// newGroupPickerView creates a view with no key chosen; pickKey opens the
+// picker and comes before any extra keys
+func newGroupPickerView(name, label, emptyHint string, pickKey rune,
+	list func(types.CostExplorerAPI, types.Query) ([]string, error),
+	fetchKey func(types.CostExplorerAPI, types.Query, string) (types.CostData, error),
+	extra ...KeyBinding) *groupPickerView {
+	v := &groupPickerView{label: label, emptyHint: emptyHint, list: list, fetchKey: fetchKey}
+	v.key.Store("")
+	keys := append([]KeyBinding{{Key: pickKey, Help: "choose " + label, Action: func(state *types.AppState) {
+		OpenGroupPicker(state, v)
+	}}}, extra...)
+	v.tableView = newTableView(name, v.fetchGroups, keys...)
+	return v
+}
+
+// Key returns the chosen key, or an empty string
+func (v *groupPickerView) Key() string {
+	return v.key.Load().(string)
+}
+
+// SetKey chooses the key to group by
+func (v *groupPickerView) SetKey(key string) {
+	v.key.Store(key)
+}
+
+// keyTitle returns the label capitalized for titles, e.g. "Tag key"
+func (v *groupPickerView) keyTitle() string {
+	return strings.ToUpper(v.label[:1]) + v.label[1:]
+}
+
+// Variant returns the chosen key
+func (v *groupPickerView) Variant() string {
+	return v.Key()
+}
+
+// fetchGroups loads costs for the chosen key, or lists the keys to choose from
+func (v *groupPickerView) fetchGroups(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	if key := v.Key(); key != "" {
+		return v.fetchKey(client, q, key)
+	}
+
+	keys, err := v.list(client, q)
+	if err != nil {
+		return types.CostData{}, err
+	}
+
+	data := types.CostData{
+		Title:   v.Name(),
+		Columns: []types.Column{{Title: v.keyTitle()}},
+		Notice:  fmt.Sprintf("Press '%c' to choose the %s to group costs by", v.keys[0].Key, v.label),
+	}
+	for _, key := range keys {
+		data.Rows = append(data.Rows, []types.Cell{types.TextCell(key)})
+	}
+	if len(keys) == 0 {
+		data.Notice = v.emptyHint
+	}
+	return data, nil
+}
+
+// Registry holds the views in menu order and drives the menu, the preloading
+// and the dispatch of selections
+type Registry struct {
+	views  []View
+	byName map[string]View
+}
+
+// NewRegistry creates a registry with the given views in menu order
+func NewRegistry(views ...View) *Registry {
+	registry := &Registry{byName: make(map[string]View)}
+	for _, view := range views {
+		registry.Register(view)
+	}
+	return registry
+}
+
+// Register appends a view to the menu, replacing any view with the same name
+func (r *Registry) Register(view View) {
+	if _, exists := r.byName[view.Name()]; exists {
+		for i, existing := range r.views {
+			if existing.Name() == view.Name() {
+				r.views[i] = view
+			}
+		}
+	} else {
+		r.views = append(r.views, view)
+	}
+	r.byName[view.Name()] = view
+}
+
+// Views returns the registered views in menu order
+func (r *Registry) Views() []View {
+	return r.views
+}
+
+// Names returns the menu labels in order
+func (r *Registry) Names() []string {
+	names := make([]string, len(r.views))
+	for i, view := range r.views {
+		names[i] = view.Name()
+	}
+	return names
+}
+
+// Lookup finds a view by name
+func (r *Registry) Lookup(name string) (View, bool) {
+	view, exists := r.byName[name]
+	return view, exists
+}
+
+// newRightsizingView creates the rightsizing view, whose recommendations
+// depend on the profile alone
+func newRightsizingView() *detailTableView {
+	v := newDetailTableView("Rightsizing", []string{aws.RightsizingSavingsColumn, aws.RightsizingCostColumn, aws.RightsizingCPUColumn},
+		aws.GetRightsizingData)
+	v.scope = profileScope
+	return v
+}
+
+// DefaultRegistry returns the built-in views in menu order
+func DefaultRegistry() *Registry {
+	return NewRegistry(
+		newTableView("Dashboard", aws.GetDashboardData),
+		newTableView("Current Month", aws.GetCurrentMonthData),
+		newAnomalyView(),
+		newForecastView(),
+		newDrillView("By Service", "SERVICE", 0, drillLevels, aws.GetServiceData, historyKeys()...),
+		newDrillView("By Region", "REGION", 0, drillLevels, aws.GetRegionData),
+		newModeView("Reservations", aws.CommitmentModes, aws.GetReservationData),
+		newTableView("By Usage Type", aws.GetUsageTypeData),
+		newDrillView("By Account", "LINKED_ACCOUNT", 1, append([]string{"SERVICE"}, drillLevels...), aws.GetAccountData, historyKeys()...),
+		newGroupPickerView("By Tag", "tag key", "No cost allocation tags found; activate them in the Billing console", 't',
+			aws.GetTagKeys, aws.GetTagData),
+		newGroupPickerView("By Cost Category", "cost category", "No cost categories found; create them in the Billing console", 'c',
+			aws.GetCostCategoryNames, aws.GetCostCategoryData, historyKeys()...),
+		newModeView("Savings Plans", aws.CommitmentModes, aws.GetSavingsPlansData),
+		newRightsizingView(),
+		newPurchaseView(),
+		newTableView("Daily", aws.GetDailyData),
+		newHourlyView(),
+	)
+}
+
+// historyKeys are the keys of monthly views that change the months of history
+func historyKeys() []KeyBinding {
+	return []KeyBinding{
+		{Key: '+', Help: "more months", Action: func(state *types.AppState) { AdjustHistory(state, 1) }},
+		{Key: '-', Help: "fewer months", Action: func(state *types.AppState) { AdjustHistory(state, -1) }},
+	}
+}
+
+// registry is the set of views shown by the application. It is assigned in
+// init because view key bindings refer back to functions that use it.
+var registry *Registry
+
+func init() {
+	registry = DefaultRegistry()
+}
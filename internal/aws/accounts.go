@@ -0,0 +1,86 @@
+package aws
+
+import (
+	"context"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// GetAccountData fetches costs grouped by linked account with one column per
+// month, covering the query's months of history unless a range is set. The
+// account names are shown next to the IDs.
+func GetAccountData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	now := time.Now()
+	dateRange := historyRange(q, now)
+	period := toInterval(dateRange)
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q),
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeDimension,
+			Key:  &[]string{"LINKED_ACCOUNT"}[0],
+		}},
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	data := monthlyData(result.ResultsByTime, metric, dateRange, now, "Account ID", func(key string) (string, bool) {
+		return key, true
+	})
+	data.Title = "🏢 Accounts"
+
+	// Names are a convenience; show the IDs alone when they cannot be resolved
+	names, err := getAccountNames(ctx, client, period)
+	if err != nil {
+		data.Notice = "Account names unavailable: " + classifyError(ctx, "GetDimensionValues", err).Error()
+	}
+
+	data.Columns = append([]types.Column{data.Columns[0], {Title: "Account Name"}}, data.Columns[1:]...)
+	for i, row := range data.Rows {
+		name := types.TextCell(names[row[0].Text])
+		data.Rows[i] = append([]types.Cell{row[0], name}, row[1:]...)
+	}
+
+	return data, nil
+}
+
+// getAccountNames maps linked account IDs to the names Cost Explorer returns
+// in the "description" attribute of the LINKED_ACCOUNT dimension values
+func getAccountNames(ctx context.Context, client types.CostExplorerAPI, period awstypes.DateInterval) (map[string]string, error) {
+	input := &costexplorer.GetDimensionValuesInput{
+		TimePeriod: &period,
+		Dimension:  awstypes.DimensionLinkedAccount,
+	}
+
+	names := make(map[string]string)
+	for {
+		page, err := client.GetDimensionValues(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range page.DimensionValues {
+			names[aws.ToString(value.Value)] = value.Attributes["description"]
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	return names, nil
+}
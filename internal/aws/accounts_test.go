@@ -0,0 +1,27 @@
+package aws
+
+import (
+	"testing"
+
+	"cost-explorer/internal/types"
+)
+
+func TestGetAccountDataExcludesRecordTypes(t *testing.T) {
+	client := NewFakeClient()
+
+	all, err := GetAccountData(client, types.Query{Months: 1})
+	if err != nil {
+		t.Fatalf("GetAccountData: %v", err)
+	}
+	usage, err := GetAccountData(client, types.Query{Months: 1, ExcludedRecordTypes: []string{"Credit", "Refund", "Tax"}})
+	if err != nil {
+		t.Fatalf("GetAccountData with excluded record types: %v", err)
+	}
+
+	if len(all.Rows) == 0 || len(usage.Rows) != len(all.Rows) {
+		t.Fatalf("got %d and %d rows, want the same accounts", len(all.Rows), len(usage.Rows))
+	}
+	if usage.Rows[0][2].Value == all.Rows[0][2].Value {
+		t.Errorf("account costs ignored the excluded record types: both %.2f", all.Rows[0][2].Value)
+	}
+}
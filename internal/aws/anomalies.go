@@ -0,0 +1,272 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// Columns of the anomaly table the view sorts by and counts unreviewed rows in
+const (
+	AnomalyImpactColumn   = "Impact"
+	AnomalyPercentColumn  = "Impact %"
+	AnomalyFeedbackColumn = "Feedback"
+)
+
+// AnomalyFeedbackOptions are the answers to whether an anomaly is one, in the
+// order offered
+var AnomalyFeedbackOptions = []string{"Yes", "No", "Planned activity"}
+
+// anomalyFeedbackValues maps AnomalyFeedbackOptions to the API values
+var anomalyFeedbackValues = map[string]awstypes.AnomalyFeedbackType{
+	"Yes":              awstypes.AnomalyFeedbackTypeYes,
+	"No":               awstypes.AnomalyFeedbackTypeNo,
+	"Planned activity": awstypes.AnomalyFeedbackTypePlannedActivity,
+}
+
+// GetAnomalyData fetches the cost anomalies detected over the query's range,
+// or its months of history, with their monitor and main root cause. The
+// anomaly ID of each row is its row key, for feedback.
+func GetAnomalyData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dateRange := throughToday(historyRange(q, time.Now()), time.Now())
+
+	monitors, err := anomalyMonitorNames(ctx, client)
+	if err != nil {
+		return types.CostData{}, err
+	}
+
+	// Unlike other requests, the end date of the interval is inclusive
+	input := &costexplorer.GetAnomaliesInput{
+		DateInterval: &awstypes.AnomalyDateInterval{
+			StartDate: aws.String(dateRange.Start.Format("2006-01-02")),
+			EndDate:   aws.String(dateRange.End.AddDate(0, 0, -1).Format("2006-01-02")),
+		},
+	}
+
+	var anomalies []awstypes.Anomaly
+	for {
+		page, err := client.GetAnomalies(ctx, input)
+		if err != nil {
+			return types.CostData{}, classifyError(ctx, "GetAnomalies", err)
+		}
+		anomalies = append(anomalies, page.Anomalies...)
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	data := types.CostData{
+		Title: fmt.Sprintf("🚨 Anomalies (%s) - %d detected", dateRange, len(anomalies)),
+		Columns: []types.Column{
+			{Title: "Start"},
+			{Title: "End"},
+			{Title: "Monitor"},
+			{Title: AnomalyImpactColumn, Kind: types.KindMoney, Unit: "USD"},
+			{Title: AnomalyPercentColumn, Kind: types.KindPercent},
+			{Title: "Service"},
+			{Title: "Account"},
+			{Title: "Region"},
+			{Title: "Usage Type"},
+			{Title: AnomalyFeedbackColumn},
+		},
+	}
+
+	for _, anomaly := range anomalies {
+		row, details := anomalyRow(anomaly, monitors)
+		data.Rows = append(data.Rows, row)
+		data.Details = append(data.Details, details)
+		data.RowKeys = append(data.RowKeys, []string{aws.ToString(anomaly.AnomalyId)})
+	}
+
+	switch {
+	case len(monitors) == 0:
+		data.Notice = "No anomaly monitors; create one in the Cost Anomaly Detection console"
+	case len(anomalies) == 0:
+		data.Notice = "No anomalies detected for " + dateRange.String()
+	case !q.Filter.IsZero():
+		data.Notice = filterIgnoredNotice("anomalies, which are scoped by their monitors")
+	}
+	return data, nil
+}
+
+// anomalyMonitorNames maps the ARN of each anomaly monitor to its name
+func anomalyMonitorNames(ctx context.Context, client types.CostExplorerAPI) (map[string]string, error) {
+	names := make(map[string]string)
+	input := &costexplorer.GetAnomalyMonitorsInput{}
+	for {
+		page, err := client.GetAnomalyMonitors(ctx, input)
+		if err != nil {
+			return nil, classifyError(ctx, "GetAnomalyMonitors", err)
+		}
+		for _, monitor := range page.AnomalyMonitors {
+			names[aws.ToString(monitor.MonitorArn)] = aws.ToString(monitor.MonitorName)
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+	return names, nil
+}
+
+// anomalyRow builds the table row of an anomaly, showing its largest root
+// cause, and details listing every root cause
+func anomalyRow(anomaly awstypes.Anomaly, monitors map[string]string) ([]types.Cell, []types.DetailField) {
+	impact := anomaly.Impact
+	if impact == nil {
+		impact = &awstypes.Impact{}
+	}
+	score := anomaly.AnomalyScore
+	if score == nil {
+		score = &awstypes.AnomalyScore{}
+	}
+
+	monitor := monitors[aws.ToString(anomaly.MonitorArn)]
+	if monitor == "" {
+		monitor = aws.ToString(anomaly.MonitorArn)
+	}
+
+	end := aws.ToString(anomaly.AnomalyEndDate)
+	if end == "" {
+		end = "ongoing"
+	}
+
+	// Largest contribution first
+	causes := append([]awstypes.RootCause(nil), anomaly.RootCauses...)
+	sort.SliceStable(causes, func(i, j int) bool {
+		return rootCauseContribution(causes[i]) > rootCauseContribution(causes[j])
+	})
+	var top awstypes.RootCause
+	if len(causes) > 0 {
+		top = causes[0]
+	}
+
+	row := []types.Cell{
+		types.TextCell(anomalyDate(anomaly.AnomalyStartDate)),
+		types.TextCell(anomalyDate(&end)),
+		types.TextCell(monitor),
+		types.ValueCell(impact.TotalImpact),
+		optionalFloatCell(impact.TotalImpactPercentage),
+		types.TextCell(aws.ToString(top.Service)),
+		types.TextCell(rootCauseAccount(top)),
+		types.TextCell(aws.ToString(top.Region)),
+		types.TextCell(aws.ToString(top.UsageType)),
+		types.TextCell(anomalyFeedbackLabel(anomaly.Feedback)),
+	}
+
+	money := func(title string, cell types.Cell) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindMoney, Unit: "USD"}, Cell: cell}
+	}
+	text := func(title, value string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title}, Cell: types.TextCell(value)}
+	}
+
+	details := []types.DetailField{
+		text("Anomaly ID", aws.ToString(anomaly.AnomalyId)),
+		text("Monitor", monitor),
+		text("Dimension", aws.ToString(anomaly.DimensionValue)),
+		text("Feedback", anomalyFeedbackLabel(anomaly.Feedback)),
+		text("Start", anomalyDate(anomaly.AnomalyStartDate)),
+		text("End", anomalyDate(&end)),
+		money("Total Impact", types.ValueCell(impact.TotalImpact)),
+		{Column: types.Column{Title: "Impact %", Kind: types.KindPercent}, Cell: optionalFloatCell(impact.TotalImpactPercentage)},
+		money("Max Daily Impact", types.ValueCell(impact.MaxImpact)),
+		money("Actual Spend", optionalFloatCell(impact.TotalActualSpend)),
+		money("Expected Spend", optionalFloatCell(impact.TotalExpectedSpend)),
+		{Column: types.Column{Title: "Score (current/max)"}, Cell: types.TextCell(fmt.Sprintf("%.2f / %.2f", score.CurrentScore, score.MaxScore))},
+	}
+	for i, cause := range causes {
+		label := fmt.Sprintf("Cause %d", i+1)
+		var parts []string
+		for _, part := range []string{aws.ToString(cause.Service), rootCauseAccount(cause), aws.ToString(cause.Region), aws.ToString(cause.UsageType)} {
+			if part != "" {
+				parts = append(parts, part)
+			}
+		}
+		details = append(details,
+			text(label, strings.Join(parts, " / ")),
+			money(label+" Impact", types.ValueCell(rootCauseContribution(cause))),
+		)
+	}
+
+	return row, details
+}
+
+// SubmitAnomalyFeedback records whether an anomaly is one, with an answer
+// from AnomalyFeedbackOptions
+func SubmitAnomalyFeedback(client types.CostExplorerAPI, anomalyID, feedback string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	value, exists := anomalyFeedbackValues[feedback]
+	if !exists {
+		return fmt.Errorf("unknown anomaly feedback %q", feedback)
+	}
+
+	_, err := client.ProvideAnomalyFeedback(ctx, &costexplorer.ProvideAnomalyFeedbackInput{
+		AnomalyId: aws.String(anomalyID),
+		Feedback:  value,
+	})
+	if err != nil {
+		return classifyError(ctx, "ProvideAnomalyFeedback", err)
+	}
+	return nil
+}
+
+// anomalyFeedbackLabel returns the answer given for an anomaly, or an empty
+// string when it has not been reviewed
+func anomalyFeedbackLabel(feedback awstypes.AnomalyFeedbackType) string {
+	for label, value := range anomalyFeedbackValues {
+		if value == feedback {
+			return label
+		}
+	}
+	return ""
+}
+
+// anomalyDate trims an anomaly date, which may carry a time, to the day
+func anomalyDate(date *string) string {
+	value := aws.ToString(date)
+	if len(value) > len("2006-01-02") && value[4] == '-' {
+		return value[:len("2006-01-02")]
+	}
+	return value
+}
+
+// rootCauseAccount names the account of a root cause, preferring its name
+func rootCauseAccount(cause awstypes.RootCause) string {
+	if name := aws.ToString(cause.LinkedAccountName); name != "" {
+		return name
+	}
+	return aws.ToString(cause.LinkedAccount)
+}
+
+// rootCauseContribution returns the impact of a root cause
+func rootCauseContribution(cause awstypes.RootCause) float64 {
+	if cause.Impact == nil {
+		return 0
+	}
+	return cause.Impact.Contribution
+}
+
+// optionalFloatCell is optionalCell for values Cost Explorer returns as numbers
+func optionalFloatCell(value *float64) types.Cell {
+	if value == nil {
+		return types.BlankCell()
+	}
+	return types.ValueCell(*value)
+}
@@ -7,9 +7,19 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 )
 
-// NewClient creates a new AWS Cost Explorer client
-func NewClient() (*costexplorer.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.TODO())
+// NewClient creates a new AWS Cost Explorer client for a shared config
+// profile and region. Empty values leave them to the environment and the
+// default profile.
+func NewClient(profile, region string) (*costexplorer.Client, error) {
+	var options []func(*config.LoadOptions) error
+	if profile != "" {
+		options = append(options, config.WithSharedConfigProfile(profile))
+	}
+	if region != "" {
+		options = append(options, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.TODO(), options...)
 	if err != nil {
 		return nil, err
 	}
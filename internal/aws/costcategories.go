@@ -0,0 +1,78 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// UncategorizedLabel is the row label for spend not matched by any rule of
+// the cost category
+const UncategorizedLabel = "uncategorized"
+
+// GetCostCategoryNames lists the cost categories with spend in the query's
+// range, or its months of history, sorted by name
+func GetCostCategoryNames(client types.CostExplorerAPI, q types.Query) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	period := toInterval(historyRange(q, time.Now()))
+	input := &costexplorer.GetCostCategoriesInput{TimePeriod: &period}
+
+	var names []string
+	for {
+		page, err := client.GetCostCategories(ctx, input)
+		if err != nil {
+			return nil, classifyError(ctx, "GetCostCategories", err)
+		}
+		names = append(names, page.CostCategoryNames...)
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// GetCostCategoryData fetches costs grouped by the values of a cost category
+// with one column per month, covering the query's months of history unless
+// a range is set
+func GetCostCategoryData(client types.CostExplorerAPI, q types.Query, category string) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	now := time.Now()
+	dateRange := historyRange(q, now)
+	period := toInterval(dateRange)
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeCostCategory,
+			Key:  &category,
+		}},
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	data := monthlyData(result.ResultsByTime, metric, dateRange, now, category, func(key string) (string, bool) {
+		return groupValue(key, UncategorizedLabel), true
+	})
+	data.Title = fmt.Sprintf("🗂️ Cost Category: %s", category)
+	return data, nil
+}
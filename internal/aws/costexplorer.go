@@ -3,6 +3,7 @@ package aws
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"time"
@@ -14,74 +15,77 @@ import (
 	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
 )
 
-// getCurrentMonthPeriod returns the now month date interval
-func getCurrentMonthPeriod() awstypes.DateInterval {
-	now := time.Now()
-	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, 0) // AWS expects exclusive end date
+// GetDashboardData fetches dashboard overview data with now month and forecast
+func GetDashboardData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	return awstypes.DateInterval{
-		Start: aws.String(start.Format("2006-01-02")),
-		End:   aws.String(end.Format("2006-01-02")),
-	}
-}
+	metric := queryMetric(q.Metric)
 
-// getPreviousMonthPeriod returns the previous month date interval
-func getPreviousMonthPeriod() awstypes.DateInterval {
-	now := time.Now()
-	start := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	var rows [][]types.Cell
+	unit := ""
 
-	return awstypes.DateInterval{
-		Start: aws.String(start.Format("2006-01-02")),
-		End:   aws.String(end.Format("2006-01-02")),
-	}
-}
+	// Get costs for the selected range, the now month by default
+	dateRange := rangeOrDefault(q, currentMonthRange(time.Now()))
+	currentPeriod := toInterval(dateRange)
+	currentResult, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &currentPeriod,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
+	})
 
-// getNextMonthPeriod returns the next month date interval
-func getNextMonthPeriod() awstypes.DateInterval {
-	now := time.Now()
-	start := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
-	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
 
-	return awstypes.DateInterval{
-		Start: aws.String(start.Format("2006-01-02")),
-		End:   aws.String(end.Format("2006-01-02")),
+	costType := "Current Month Total"
+	if !q.Range.IsZero() {
+		costType = "Total"
 	}
-}
 
-// GetDashboardData fetches dashboard overview data with now month and forecast
-func GetDashboardData(client *costexplorer.Client) types.CostData {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	var rangeTotal float64
+	for _, resultByTime := range currentResult.ResultsByTime {
+		if amount, amountUnit, exists := metricAmount(resultByTime.Total, metric); exists {
+			unit = amountUnit
+			rangeTotal += amount
+			rows = append(rows, []types.Cell{
+				types.TextCell(periodLabel(resultByTime.TimePeriod)),
+				types.TextCell(costType),
+				types.ValueCell(amount),
+			})
+		}
+	}
 
-	rows := [][]string{
-		{"Period", "Cost Type", "Amount"},
+	// Ranges spanning several months also get a grand total
+	if len(rows) > 1 {
+		rows = append(rows, []types.Cell{
+			types.TextCell(dateRange.String()),
+			types.TextCell("Range Total"),
+			types.ValueCell(rangeTotal),
+		})
 	}
 
-	// Get now month data
-	currentPeriod := getCurrentMonthPeriod()
-	currentResult, err := client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+	// Break the range down by record type, so credits, refunds and taxes show
+	breakdown, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
 		TimePeriod:  &currentPeriod,
 		Granularity: awstypes.GranularityMonthly,
-		Metrics:     []string{"NetUnblendedCost"},
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
+		GroupBy: []awstypes.GroupDefinition{
+			{
+				Type: awstypes.GroupDefinitionTypeDimension,
+				Key:  aws.String(string(awstypes.DimensionRecordType)),
+			},
+		},
 	})
 
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Current Month", "Timeout", "Request timed out after 30 seconds"})
-		} else {
-			rows = append(rows, []string{"Current Month", "Error", err.Error()})
-		}
-	} else {
-		for _, resultByTime := range currentResult.ResultsByTime {
-			currentMonthName := time.Now().Format("January 2006")
-			if netCost, exists := resultByTime.Total["NetUnblendedCost"]; exists {
-				rows = append(rows, []string{currentMonthName, "Current Month Total", formatCost(netCost.Amount)})
-			}
-		}
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
 	}
 
+	rows = append(rows, recordTypeRows(breakdown.ResultsByTime, metric, dateRange, q.ExcludedRecordTypes)...)
+
 	// Get forecast data for now month (month-to-date projection)
 	now := time.Now()
 	currentMonthEnd := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
@@ -93,278 +97,228 @@ func GetDashboardData(client *costexplorer.Client) types.CostData {
 	forecast, err := client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
 		TimePeriod:  &forecastPeriod,
 		Granularity: awstypes.GranularityMonthly,
-		Metric:      awstypes.MetricNetUnblendedCost,
+		Metric:      forecastMetric(metric),
+		Filter:      queryFilter(q),
 	})
 
+	// Forecasts fail routinely, e.g. for new accounts or narrow filters, so
+	// the totals are still shown without one
+	notice := ""
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Current Month", "Timeout", "Request timed out after 30 seconds"})
-		} else {
-			rows = append(rows, []string{"Current Month", "Error", err.Error()})
+		kind := errorKind(ctx, err)
+		notice = fmt.Sprintf("No forecast: %s", kind)
+		if hint := kind.Hint(); hint != "" {
+			notice += " - " + hint
 		}
 	} else {
 		currentMonthName := time.Now().Format("January 2006")
-		rows = append(rows, []string{currentMonthName, "Forecasted Total", formatCost(forecast.Total.Amount)})
+		rows = append(rows, []types.Cell{
+			types.TextCell(currentMonthName),
+			types.TextCell("Forecasted Total"),
+			types.ValueCell(metricValueAmount(forecast.Total)),
+		})
 	}
 
-	return types.CostData{Title: "💸 Dashboard Overview", Rows: rows}
+	return types.CostData{
+		Title: "💸 Dashboard Overview",
+		Columns: []types.Column{
+			{Title: "Period"},
+			{Title: "Cost Type"},
+			{Title: "Amount", Kind: types.KindMoney, Unit: unit},
+		},
+		Rows:   rows,
+		Notice: notice,
+	}, nil
 }
 
-// GetForecastData fetches cost forecast data
-func GetForecastData(client *costexplorer.Client) types.CostData {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	period := getNextMonthPeriod()
-
-	forecast, err := client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
-		TimePeriod:  &period,
-		Granularity: awstypes.GranularityMonthly,
-		Metric:      awstypes.MetricNetUnblendedCost,
-	})
-
-	rows := [][]string{
-		{"Forecast Type", "Amount", "Period"},
-	}
-
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Timeout", "Request timed out after 30 seconds", ""})
-		} else {
-			rows = append(rows, []string{"Error", err.Error(), ""})
-		}
-		return types.CostData{Title: "Cost Forecast", Rows: rows}
-	}
-
-	periodStr := fmt.Sprintf("%s to %s", *period.Start, *period.End)
-	rows = append(rows, []string{"Predicted Total Cost", formatCost(forecast.Total.Amount), periodStr})
-
-	if forecast.ForecastResultsByTime != nil {
-		for _, forecastResult := range forecast.ForecastResultsByTime {
-			forecastPeriod := fmt.Sprintf("%s to %s", *forecastResult.TimePeriod.Start, *forecastResult.TimePeriod.End)
-			rows = append(rows, []string{"Mean Estimate", formatCost(forecastResult.MeanValue), forecastPeriod})
+// recordTypeRows totals each record type over the range, largest first, marking
+// those left out of the per-service, per-region and per-account views
+func recordTypeRows(results []awstypes.ResultByTime, metric string, dateRange types.DateRange, excluded []string) [][]types.Cell {
+	totals := make(map[string]float64)
+	for _, resultByTime := range results {
+		for _, group := range resultByTime.Groups {
+			if len(group.Keys) == 0 || group.Metrics == nil {
+				continue
+			}
+			if amount, _, exists := metricAmount(group.Metrics, metric); exists && amount != 0 {
+				totals[group.Keys[0]] += amount
+			}
 		}
 	}
 
-	return types.CostData{Title: "🔮 Cost Forecast", Rows: rows}
-}
-
-// getThreeMonthPeriod returns a date interval covering now month and previous two months
-func getThreeMonthPeriod() awstypes.DateInterval {
-	now := time.Now()
-	// Start from 2 months ago
-	start := time.Date(now.Year(), now.Month()-2, 1, 0, 0, 0, 0, time.UTC)
-	// End at the start of next month (AWS expects exclusive end date)
-	end := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
-
-	return awstypes.DateInterval{
-		Start: aws.String(start.Format("2006-01-02")),
-		End:   aws.String(end.Format("2006-01-02")),
+	recordTypes := make([]string, 0, len(totals))
+	for recordType := range totals {
+		recordTypes = append(recordTypes, recordType)
 	}
-}
-
-// normalizeServiceName standardizes service names to match console display
-func normalizeServiceName(serviceName string) string {
-	// Common service name mappings from API to console display names
-	serviceMap := map[string]string{
-		"Amazon Elastic Compute Cloud - Compute": "Amazon Elastic Compute Cloud",
-		"Amazon Elastic Container Service":       "Amazon ECS",
-		"Amazon EC2 Container Service":           "Amazon ECS",
-		"Amazon Elastic Load Balancing":          "Elastic Load Balancing",
-		"AWS Data Transfer":                      "Data Transfer",
-		"Amazon CloudFront":                      "CloudFront",
-		"Amazon Virtual Private Cloud":           "Amazon VPC",
-		"AWS WAF":                                "AWS WAF",
-		"Amazon Relational Database Service":     "Amazon RDS",
-		// Additional potential Data Transfer variations
-		"Data Transfer":        "Data Transfer",
-		"AWS DataTransfer":     "Data Transfer",
-		"Amazon Data Transfer": "Data Transfer",
-		"EC2 - Other":          "Data Transfer", // Data transfer costs appear here
-		"EC2-Other":            "Data Transfer", // Alternative format
-		"Amazon Elastic Compute Cloud - Data Transfer": "Data Transfer",
-	}
-
-	if normalized, exists := serviceMap[serviceName]; exists {
-		return normalized
-	}
-	return serviceName
-}
+	sort.Slice(recordTypes, func(i, j int) bool {
+		return totals[recordTypes[i]] > totals[recordTypes[j]]
+	})
 
-// isTaxService checks if a service name represents tax and should be excluded
-func isTaxService(serviceName string) bool {
-	taxServices := []string{
-		"Tax",
-		"AWS Tax",
-		"Amazon Tax",
-		"Sales Tax",
-		"VAT",
-		"GST",
-		"Tax Service",
-		"Taxation",
-		"AWS Sales Tax",
-		"Amazon Sales Tax",
-	}
-
-	for _, taxService := range taxServices {
-		if serviceName == taxService {
-			return true
+	rows := make([][]types.Cell, 0, len(recordTypes))
+	for _, recordType := range recordTypes {
+		label := recordType
+		if slices.Contains(excluded, recordType) {
+			label += " (excluded)"
 		}
+		rows = append(rows, []types.Cell{
+			types.TextCell(dateRange.String()),
+			types.TextCell(label),
+			types.ValueCell(totals[recordType]),
+		})
 	}
-	return false
+	return rows
 }
 
-// GetServiceData fetches costs grouped by service for now month and previous two months
-func GetServiceData(client *costexplorer.Client) types.CostData {
+// GetServiceData fetches costs grouped by service with one column per month,
+// covering the query's months of history unless a range is set
+func GetServiceData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	period := getThreeMonthPeriod()
-	result, err := client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+	metric := queryMetric(q.Metric)
+
+	now := time.Now()
+	dateRange := historyRange(q, now)
+	period := toInterval(dateRange)
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
 		TimePeriod:  &period,
 		Granularity: awstypes.GranularityMonthly,
-		Metrics:     []string{"NetUnblendedCost"},
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q),
 		GroupBy: []awstypes.GroupDefinition{{
 			Type: awstypes.GroupDefinitionTypeDimension,
 			Key:  &[]string{"SERVICE"}[0],
 		}},
 	})
 
-	now := time.Now()
-	currentMonthKey := now.Format("Jan")
-	prevMonth1 := now.AddDate(0, -1, 0).Format("Jan")
-	prevMonth2 := now.AddDate(0, -2, 0).Format("Jan")
-
-	rows := [][]string{
-		{"Service", "Now", prevMonth1, prevMonth2},
-	}
-
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Current Month", "Timeout", "Request timed out after 30 seconds"})
-		} else {
-			rows = append(rows, []string{"Current Month", "Error", err.Error()})
-		}
-		return types.CostData{Title: "Costs by Service", Rows: rows}
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
 	}
 
-	// Map to store service costs by month: service -> month -> cost
-	serviceMonthCosts := make(map[string]map[string]float64)
-	monthTotals := make(map[string]float64)
-	for _, resultByTime := range result.ResultsByTime {
+	data := monthlyData(result.ResultsByTime, metric, dateRange, now, "Service", func(key string) (string, bool) {
+		return normalizeServiceName(key), true
+	})
+	data.Title = "🛠️Services"
+	return data, nil
+}
+
+// monthlyData builds a table with one row per group and one column per month
+// of the range, newest first. name maps a group key to its row label and
+// reports whether to keep the group; keys mapping to the same label are
+// combined, and the label marked with AliasMarker.
+func monthlyData(results []awstypes.ResultByTime, metric string, dateRange types.DateRange, now time.Time, nameTitle string, name func(key string) (string, bool)) types.CostData {
+	// Columns run from the most recent month back to the oldest
+	months := monthStarts(dateRange)
+	for i, j := 0, len(months)-1; i < j; i, j = i+1, j-1 {
+		months[i], months[j] = months[j], months[i]
+	}
+
+	// Map to store group costs by month: group -> month -> cost, along with
+	// the raw keys combined into each group
+	groupMonthCosts := make(map[string]map[string]float64)
+	groupKeys := make(map[string][]string)
+	unit := ""
+	for _, resultByTime := range results {
 		// Parse the month from the time period
 		startDate, err := time.Parse("2006-01-02", *resultByTime.TimePeriod.Start)
 		if err != nil {
 			continue
 		}
-		monthKey := startDate.Format("Jan")
+		monthKey := startDate.Format("2006-01")
 
 		for _, group := range resultByTime.Groups {
 			if len(group.Keys) > 0 && group.Metrics != nil {
-				rawServiceName := group.Keys[0]
-				serviceName := normalizeServiceName(rawServiceName)
-
-				// Skip tax services
-				if isTaxService(serviceName) || isTaxService(rawServiceName) {
+				groupName, keep := name(group.Keys[0])
+				if !keep {
 					continue
 				}
 
-				if netCost, exists := group.Metrics["NetUnblendedCost"]; exists && netCost.Amount != nil {
-					if amount, err := strconv.ParseFloat(*netCost.Amount, 64); err == nil && amount > 0 {
-						if serviceMonthCosts[serviceName] == nil {
-							serviceMonthCosts[serviceName] = make(map[string]float64)
-						}
-						serviceMonthCosts[serviceName][monthKey] += amount // Add to existing amount instead of overwriting
-						monthTotals[monthKey] += amount
+				// Credits and refunds are negative and kept like any other amount
+				if amount, amountUnit, exists := metricAmount(group.Metrics, metric); exists && amount != 0 {
+					if groupMonthCosts[groupName] == nil {
+						groupMonthCosts[groupName] = make(map[string]float64)
 					}
+					if !slices.Contains(groupKeys[groupName], group.Keys[0]) {
+						groupKeys[groupName] = append(groupKeys[groupName], group.Keys[0])
+					}
+					groupMonthCosts[groupName][monthKey] += amount // Add to existing amount instead of overwriting
+					unit = amountUnit
 				}
 			}
 		}
 	}
 
-	// Create service groups for sorting
-	type ServiceData struct {
-		Name   string
-		Month0 float64 // Current month
-		Month1 float64 // Previous month
-		Month2 float64 // 2 months ago
+	// Create group rows for sorting, with costs in column order
+	type GroupData struct {
+		Name  string
+		Costs []float64
+		Total float64
 	}
 
-	var services []ServiceData
-	for serviceName, monthCosts := range serviceMonthCosts {
-		service := ServiceData{
-			Name:   serviceName,
-			Month0: monthCosts[currentMonthKey],
-			Month1: monthCosts[prevMonth1],
-			Month2: monthCosts[prevMonth2],
+	var groups []GroupData
+	for groupName, monthCosts := range groupMonthCosts {
+		group := GroupData{Name: groupName, Costs: make([]float64, len(months))}
+		for i, month := range months {
+			group.Costs[i] = monthCosts[month.Format("2006-01")]
+			group.Total += group.Costs[i]
 		}
-		services = append(services, service)
+		groups = append(groups, group)
 	}
 
-	// Sort by now month cost first (primary), then by previous months
-	// Services with now month costs come first, then services with only past costs
-	sort.Slice(services, func(i, j int) bool {
-		// If one has now month cost and the other doesn't, prioritize now month
-		if services[i].Month0 > 0 && services[j].Month0 == 0 {
-			return true
-		}
-		if services[i].Month0 == 0 && services[j].Month0 > 0 {
-			return false
-		}
-
-		// If both have now month costs, sort by now month cost descending
-		if services[i].Month0 > 0 && services[j].Month0 > 0 {
-			return services[i].Month0 > services[j].Month0
+	// Sort by the most recent month first so groups with current costs come
+	// before those with only past costs, then by the total over all months
+	sort.Slice(groups, func(i, j int) bool {
+		if len(months) > 0 && groups[i].Costs[0] != groups[j].Costs[0] {
+			return groups[i].Costs[0] > groups[j].Costs[0]
 		}
-
-		// If neither has now month costs, sort by most recent past month cost
-		if services[i].Month1 != services[j].Month1 {
-			return services[i].Month1 > services[j].Month1
+		if groups[i].Total != groups[j].Total {
+			return groups[i].Total > groups[j].Total
 		}
-		return services[i].Month2 > services[j].Month2
+		return groups[i].Name < groups[j].Name
 	})
 
-	// Add service rows
-	for _, service := range services {
-		month0Str := "$0.00" // Now month
-		month1Str := "$0.00" // Previous month
-		month2Str := "$0.00" // 2 months ago
+	columns := []types.Column{{Title: nameTitle}}
+	columns = append(columns, monthColumns(months, now, unit)...)
 
-		if service.Month0 > 0 {
-			amount := strconv.FormatFloat(service.Month0, 'f', -1, 64)
-			month0Str = formatCost(&amount)
+	// Add group rows
+	var rows [][]types.Cell
+	var rowKeys [][]string
+	for _, group := range groups {
+		label := group.Name
+		if len(groupKeys[group.Name]) > 1 {
+			label += AliasMarker
 		}
-		if service.Month1 > 0 {
-			amount := strconv.FormatFloat(service.Month1, 'f', -1, 64)
-			month1Str = formatCost(&amount)
+		row := []types.Cell{types.TextCell(label)}
+		for _, cost := range group.Costs {
+			row = append(row, types.ValueCell(cost))
 		}
-		if service.Month2 > 0 {
-			amount := strconv.FormatFloat(service.Month2, 'f', -1, 64)
-			month2Str = formatCost(&amount)
-		}
-
-		rows = append(rows, []string{
-			service.Name,
-			month0Str, // Now month first
-			month1Str, // Previous month second
-			month2Str, // 2 months ago third
-		})
+		rows = append(rows, row)
+		rowKeys = append(rowKeys, groupKeys[group.Name])
 	}
 
-	return types.CostData{Title: "🛠️Services", Rows: rows}
+	return types.CostData{
+		Columns:      columns,
+		Rows:         rows,
+		HighlightTop: 3,
+		RowKeys:      rowKeys,
+	}
 }
 
 // GetRegionData fetches costs grouped by region
-func GetRegionData(client *costexplorer.Client) types.CostData {
+func GetRegionData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	period := getCurrentMonthPeriod()
+	metric := queryMetric(q.Metric)
+
+	dateRange := rangeOrDefault(q, currentMonthRange(time.Now()))
+	period := toInterval(dateRange)
 
-	result, err := client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
 		TimePeriod:  &period,
 		Granularity: awstypes.GranularityMonthly,
-		Metrics:     []string{"NetUnblendedCost"},
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q),
 		GroupBy: []awstypes.GroupDefinition{
 			{
 				Type: awstypes.GroupDefinitionTypeDimension,
@@ -373,40 +327,50 @@ func GetRegionData(client *costexplorer.Client) types.CostData {
 		},
 	})
 
-	rows := [][]string{
-		{"Region", "Cost (Current Month)", "Percentage"},
-	}
-
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Timeout", "Request timed out after 30 seconds", ""})
-		} else {
-			rows = append(rows, []string{"Error", err.Error(), ""})
-		}
-		return types.CostData{Title: "Regions", Rows: rows}
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
 	}
 
-	var totalCost float64
-	regionMap := make(map[string]float64)
+	costGroups, unit := groupTotals(result.ResultsByTime, metric, func(key string) string {
+		return key
+	})
 
-	for _, resultByTime := range result.ResultsByTime {
+	rows := percentageRows(costGroups)
+
+	return types.CostData{
+		Title: "🌍 Regions",
+		Columns: []types.Column{
+			{Title: "Region"},
+			{Title: fmt.Sprintf("Cost (%s)", dateRange), Kind: types.KindMoney, Unit: unit},
+			{Title: "Percentage", Kind: types.KindPercent},
+		},
+		Rows: rows,
+	}, nil
+}
+
+// groupTotals sums each group's cost over all periods, largest first, along
+// with their unit. name maps a group key to its label; keys mapping to the
+// same label are combined.
+func groupTotals(results []awstypes.ResultByTime, metric string, name func(key string) string) ([]types.CostGroup, string) {
+	groupMap := make(map[string]float64)
+	unit := ""
+
+	for _, resultByTime := range results {
 		for _, group := range resultByTime.Groups {
 			if len(group.Keys) > 0 && group.Metrics != nil {
-				regionName := group.Keys[0]
-				if netCost, exists := group.Metrics["NetUnblendedCost"]; exists && netCost.Amount != nil {
-					if amount, err := strconv.ParseFloat(*netCost.Amount, 64); err == nil && amount > 0 {
-						regionMap[regionName] += amount
-						totalCost += amount
-					}
+				groupName := name(group.Keys[0])
+				if amount, amountUnit, exists := metricAmount(group.Metrics, metric); exists && amount != 0 {
+					groupMap[groupName] += amount
+					unit = amountUnit
 				}
 			}
 		}
 	}
 
 	var costGroups []types.CostGroup
-	for regionName, amount := range regionMap {
+	for groupName, amount := range groupMap {
 		costGroups = append(costGroups, types.CostGroup{
-			Name:   regionName,
+			Name:   groupName,
 			Amount: amount,
 		})
 	}
@@ -415,30 +379,51 @@ func GetRegionData(client *costexplorer.Client) types.CostData {
 		return costGroups[i].Amount > costGroups[j].Amount
 	})
 
+	return costGroups, unit
+}
+
+// percentageRows builds name, cost and percentage rows. Percentages are of
+// the gross spend, the sum of the positive amounts, as credits and refunds
+// can bring the net total to zero or below; credits get negative percentages.
+// Without any spend the percentages are blank.
+func percentageRows(costGroups []types.CostGroup) [][]types.Cell {
+	var grossCost float64
 	for _, group := range costGroups {
-		percentage := (group.Amount / totalCost) * 100
-		amountStr := strconv.FormatFloat(group.Amount, 'f', -1, 64)
-		rows = append(rows, []string{
-			group.Name,
-			formatCost(&amountStr),
-			fmt.Sprintf("%.1f%%", percentage),
-		})
+		if group.Amount > 0 {
+			grossCost += group.Amount
+		}
 	}
 
-	return types.CostData{Title: "🌍 Regions", Rows: rows}
+	var rows [][]types.Cell
+	for _, group := range costGroups {
+		percentage := types.BlankCell()
+		if grossCost > 0 {
+			percentage = types.ValueCell(group.Amount / grossCost * 100)
+		}
+		rows = append(rows, []types.Cell{
+			types.TextCell(group.Name),
+			types.ValueCell(group.Amount),
+			percentage,
+		})
+	}
+	return rows
 }
 
 // GetUsageTypeData fetches costs grouped by usage type
-func GetUsageTypeData(client *costexplorer.Client) types.CostData {
+func GetUsageTypeData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	period := getCurrentMonthPeriod()
+	metric := queryMetric(q.Metric)
+
+	dateRange := rangeOrDefault(q, currentMonthRange(time.Now()))
+	period := toInterval(dateRange)
 
-	result, err := client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
 		TimePeriod:  &period,
 		Granularity: awstypes.GranularityMonthly,
-		Metrics:     []string{"NetUnblendedCost"},
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
 		GroupBy: []awstypes.GroupDefinition{
 			{
 				Type: awstypes.GroupDefinitionTypeDimension,
@@ -447,123 +432,163 @@ func GetUsageTypeData(client *costexplorer.Client) types.CostData {
 		},
 	})
 
-	rows := [][]string{
-		{"Usage Type", "Cost (Current Month)", "Percentage"},
-	}
-
 	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Timeout", "Request timed out after 30 seconds", ""})
-		} else {
-			rows = append(rows, []string{"Error", err.Error(), ""})
-		}
-		return types.CostData{Title: "Costs by Usage Type", Rows: rows}
-	}
-
-	var totalCost float64
-	usageTypeMap := make(map[string]float64)
-
-	for _, resultByTime := range result.ResultsByTime {
-		for _, group := range resultByTime.Groups {
-			if len(group.Keys) > 0 && group.Metrics != nil {
-				usageTypeName := group.Keys[0]
-				if netCost, exists := group.Metrics["NetUnblendedCost"]; exists && netCost.Amount != nil {
-					if amount, err := strconv.ParseFloat(*netCost.Amount, 64); err == nil && amount > 0 {
-						usageTypeMap[usageTypeName] += amount
-						totalCost += amount
-					}
-				}
-			}
-		}
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
 	}
 
-	var costGroups []types.CostGroup
-	for usageTypeName, amount := range usageTypeMap {
-		costGroups = append(costGroups, types.CostGroup{
-			Name:   usageTypeName,
-			Amount: amount,
-		})
-	}
-
-	sort.Slice(costGroups, func(i, j int) bool {
-		return costGroups[i].Amount > costGroups[j].Amount
+	costGroups, unit := groupTotals(result.ResultsByTime, metric, func(key string) string {
+		return key
 	})
 
+	// Percentages are of the top usage types shown
 	if len(costGroups) > 10 {
 		costGroups = costGroups[:10]
-		totalCost = 0
-		for _, group := range costGroups {
-			totalCost += group.Amount
-		}
 	}
 
-	for _, group := range costGroups {
-		percentage := (group.Amount / totalCost) * 100
-		amountStr := strconv.FormatFloat(group.Amount, 'f', -1, 64)
-		rows = append(rows, []string{
-			group.Name,
-			formatCost(&amountStr),
-			fmt.Sprintf("%.1f%%", percentage),
-		})
-	}
+	rows := percentageRows(costGroups)
 
-	return types.CostData{Title: "📊 Top 10 Usage Types", Rows: rows}
+	return types.CostData{
+		Title: "📊 Top 10 Usage Types",
+		Columns: []types.Column{
+			{Title: "Usage Type"},
+			{Title: fmt.Sprintf("Cost (%s)", dateRange), Kind: types.KindMoney, Unit: unit},
+			{Title: "Percentage", Kind: types.KindPercent},
+		},
+		Rows: rows,
+	}, nil
 }
 
-// GetCurrentMonthData fetches now month cost breakdown
-func GetCurrentMonthData(client *costexplorer.Client) types.CostData {
+// GetCurrentMonthData fetches the current month's total under each cost metric
+func GetCurrentMonthData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	period := getCurrentMonthPeriod()
+	period := toInterval(currentMonthRange(time.Now()))
 
-	result, err := client.GetCostAndUsage(ctx, &costexplorer.GetCostAndUsageInput{
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
 		TimePeriod:  &period,
 		Granularity: awstypes.GranularityMonthly,
 		Metrics:     []string{"BlendedCost", "UnblendedCost", "NetUnblendedCost"},
+		Filter:      queryFilter(q),
 	})
 
-	rows := [][]string{
-		{"Period", "Metric", "Amount"},
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
 	}
 
-	if err != nil {
-		if ctx.Err() == context.DeadlineExceeded {
-			rows = append(rows, []string{"Timeout", "Request timed out", ""})
-		} else {
-			rows = append(rows, []string{"Error", err.Error(), ""})
-		}
-		return types.CostData{Title: "Current Month Breakdown", Rows: rows}
+	totals := []struct {
+		Metric string
+		Label  string
+	}{
+		{"BlendedCost", "Total Blended Cost"},
+		{"UnblendedCost", "Total Unblended Cost"},
+		{"NetUnblendedCost", "Total Net Cost"},
 	}
 
+	var rows [][]types.Cell
+	unit := ""
 	for _, resultByTime := range result.ResultsByTime {
 		period := fmt.Sprintf("%s to %s", *resultByTime.TimePeriod.Start, *resultByTime.TimePeriod.End)
 
-		if blendedCost, exists := resultByTime.Total["BlendedCost"]; exists {
-			rows = append(rows, []string{period, "Total Blended Cost", formatCost(blendedCost.Amount)})
-		}
-		if unblendedCost, exists := resultByTime.Total["UnblendedCost"]; exists {
-			rows = append(rows, []string{period, "Total Unblended Cost", formatCost(unblendedCost.Amount)})
+		for _, metric := range totals {
+			if amount, amountUnit, exists := metricAmount(resultByTime.Total, metric.Metric); exists {
+				unit = amountUnit
+				rows = append(rows, []types.Cell{
+					types.TextCell(period),
+					types.TextCell(metric.Label),
+					types.ValueCell(amount),
+				})
+			}
 		}
-		if netCost, exists := resultByTime.Total["NetUnblendedCost"]; exists {
-			rows = append(rows, []string{period, "Total Net Cost", formatCost(netCost.Amount)})
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("📅 Current Month Costs (%s)", time.Now().Format("January 2006")),
+		Columns: []types.Column{
+			{Title: "Period"},
+			{Title: "Metric"},
+			{Title: "Amount", Kind: types.KindMoney, Unit: unit},
+		},
+		Rows: rows,
+	}, nil
+}
+
+// monthColumns creates one money column per month, titling the current month
+// "Now". Titles include the year when the months span more than one year.
+func monthColumns(months []time.Time, now time.Time, unit string) []types.Column {
+	layout := "Jan"
+	if len(months) > 0 && months[0].Year() != months[len(months)-1].Year() {
+		layout = "Jan 2006"
+	}
+
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	columns := make([]types.Column, 0, len(months))
+	for _, month := range months {
+		title := month.Format(layout)
+		if month.Equal(currentMonth) {
+			title = "Now"
 		}
+		columns = append(columns, types.Column{Title: title, Kind: types.KindMoney, Unit: unit})
 	}
+	return columns
+}
+
+// periodLabel names a result period: the month for whole months, otherwise the dates
+func periodLabel(period *awstypes.DateInterval) string {
+	start, err := time.Parse("2006-01-02", aws.ToString(period.Start))
+	if err != nil {
+		return aws.ToString(period.Start)
+	}
+	end, err := time.Parse("2006-01-02", aws.ToString(period.End))
+	if err != nil {
+		return aws.ToString(period.Start)
+	}
+
+	if start.Day() == 1 && end.Equal(start.AddDate(0, 1, 0)) {
+		return start.Format("January 2006")
+	}
+	return start.Format("2006-01-02") + " to " + end.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// metricAmount returns the parsed amount and unit of a metric, if present
+func metricAmount(metrics map[string]awstypes.MetricValue, metric string) (float64, string, bool) {
+	value, exists := metrics[metric]
+	if !exists || value.Amount == nil {
+		return 0, "", false
+	}
+
+	amount, err := strconv.ParseFloat(*value.Amount, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return amount, aws.ToString(value.Unit), true
+}
 
-	return types.CostData{Title: fmt.Sprintf("📅 Current Month Costs (%s)", time.Now().Format("January 2006")), Rows: rows}
+// metricValueAmount returns the parsed amount of a single metric value
+func metricValueAmount(value *awstypes.MetricValue) float64 {
+	if value == nil {
+		return 0
+	}
+	return parseAmount(value.Amount)
 }
 
-// formatCost formats a cost amount string for display
-func formatCost(amountStr *string) string {
+// metricValueUnit returns the unit of a single metric value
+func metricValueUnit(value *awstypes.MetricValue) string {
+	if value == nil {
+		return ""
+	}
+	return aws.ToString(value.Unit)
+}
+
+// parseAmount parses an amount string returned by Cost Explorer
+func parseAmount(amountStr *string) float64 {
 	if amountStr == nil {
-		return "$0.00"
+		return 0
 	}
 
 	amount, err := strconv.ParseFloat(*amountStr, 64)
 	if err != nil {
-		return "$0.00"
+		return 0
 	}
-
-	// Use standard rounding instead of always rounding up
-	return fmt.Sprintf("$%.2f", amount)
+	return amount
 }
@@ -0,0 +1,200 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fakeResults returns the fake's results for a range grouped by a dimension
+func fakeResults(t *testing.T, client *FakeClient, granularity awstypes.Granularity, dateRange types.DateRange, dimension string) []awstypes.ResultByTime {
+	t.Helper()
+
+	period := toInterval(dateRange)
+	output, err := client.GetCostAndUsage(context.Background(), &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: granularity,
+		Metrics:     []string{DefaultMetric},
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeDimension,
+			Key:  aws.String(dimension),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("GetCostAndUsage: %v", err)
+	}
+	return output.ResultsByTime
+}
+
+func TestMonthlyData(t *testing.T) {
+	now := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	dateRange := recentMonthsRange(now, 3)
+	results := fakeResults(t, NewFakeClient(), awstypes.GranularityMonthly, dateRange, "SERVICE")
+
+	tests := []struct {
+		name       string
+		keep       func(key string) bool
+		wantFirst  string
+		wantRow    string
+		wantKeys   []string
+		wantAbsent string
+	}{
+		{
+			name:      "aliases are combined and marked",
+			keep:      func(string) bool { return true },
+			wantFirst: "EC2-Instances",
+			wantRow:   "Elastic Container Service" + AliasMarker,
+			wantKeys:  []string{"Amazon Elastic Container Service", "Amazon EC2 Container Service"},
+		},
+		{
+			name:       "dropped keys have no row",
+			keep:       func(key string) bool { return key != "Amazon Elastic Compute Cloud - Compute" },
+			wantFirst:  "Relational Database Service",
+			wantRow:    "S3",
+			wantKeys:   []string{"Amazon Simple Storage Service"},
+			wantAbsent: "EC2-Instances",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := monthlyData(results, DefaultMetric, dateRange, now, "Service", func(key string) (string, bool) {
+				return normalizeServiceName(key), tt.keep(key)
+			})
+
+			if got, want := len(data.Columns), 4; got != want {
+				t.Fatalf("got %d columns, want %d", got, want)
+			}
+			if got := data.Rows[0][0].Text; got != tt.wantFirst {
+				t.Errorf("first row %q, want %q", got, tt.wantFirst)
+			}
+
+			found := false
+			for i, row := range data.Rows {
+				switch row[0].Text {
+				case tt.wantAbsent:
+					t.Errorf("row %q should have been dropped", tt.wantAbsent)
+				case tt.wantRow:
+					found = true
+					if !slices.Equal(data.RowKeys[i], tt.wantKeys) {
+						t.Errorf("row keys %v, want %v", data.RowKeys[i], tt.wantKeys)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("no row %q", tt.wantRow)
+			}
+
+			// Rows are sorted by the most recent month, largest first
+			for i := 1; i < len(data.Rows); i++ {
+				if data.Rows[i-1][1].Value < data.Rows[i][1].Value {
+					t.Errorf("row %d (%.2f) sorts before a larger row (%.2f)", i-1, data.Rows[i-1][1].Value, data.Rows[i][1].Value)
+				}
+			}
+		})
+	}
+}
+
+func TestMonthlyDataKeepsNegativeAmounts(t *testing.T) {
+	now := time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)
+	dateRange := currentMonthRange(now)
+	results := fakeResults(t, NewFakeClient(), awstypes.GranularityMonthly, dateRange, "RECORD_TYPE")
+
+	data := monthlyData(results, DefaultMetric, dateRange, now, "Record Type", func(key string) (string, bool) {
+		return key, true
+	})
+
+	var total float64
+	for _, row := range data.Rows {
+		total += row[1].Value
+		if row[0].Text == "Credit" && row[1].Value >= 0 {
+			t.Errorf("credit is %.2f, want a negative amount", row[1].Value)
+		}
+	}
+	if len(data.Rows) != len(fixtureGroups["RECORD_TYPE"]) {
+		t.Errorf("got %d rows, want %d", len(data.Rows), len(fixtureGroups["RECORD_TYPE"]))
+	}
+	if last := data.Rows[len(data.Rows)-1][0].Text; last != "Credit" && last != "Refund" {
+		t.Errorf("last row %q, want a credit or refund", last)
+	}
+	if total <= 0 {
+		t.Errorf("net total %.2f, want positive", total)
+	}
+}
+
+func TestGroupTotals(t *testing.T) {
+	dateRange := currentMonthRange(time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		dimension string
+		name      func(key string) string
+		wantFirst string
+		wantCount int
+	}{
+		{"REGION", func(key string) string { return key }, "us-east-1", len(fixtureGroups["REGION"])},
+		{"SERVICE", normalizeServiceName, "EC2-Instances", len(fixtureGroups["SERVICE"]) - 1},
+		{"LINKED_ACCOUNT", func(string) string { return "All" }, "All", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dimension, func(t *testing.T) {
+			results := fakeResults(t, NewFakeClient(), awstypes.GranularityMonthly, dateRange, tt.dimension)
+			groups, unit := groupTotals(results, DefaultMetric, tt.name)
+
+			if len(groups) != tt.wantCount {
+				t.Fatalf("got %d groups, want %d", len(groups), tt.wantCount)
+			}
+			if groups[0].Name != tt.wantFirst {
+				t.Errorf("first group %q, want %q", groups[0].Name, tt.wantFirst)
+			}
+			if unit != "USD" {
+				t.Errorf("unit %q, want USD", unit)
+			}
+
+			for i, group := range groups {
+				if i > 0 && groups[i-1].Amount < group.Amount {
+					t.Errorf("group %q sorts before the larger %q", groups[i-1].Name, group.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestPercentageRows(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []types.CostGroup
+		want   []float64 // NaN for a blank percentage
+	}{
+		{"spend only", []types.CostGroup{{Name: "a", Amount: 75}, {Name: "b", Amount: 25}}, []float64{75, 25}},
+		{"credits", []types.CostGroup{{Name: "a", Amount: 80}, {Name: "b", Amount: 20}, {Name: "credit", Amount: -10}}, []float64{80, 20, -10}},
+		{"credits cancel spend", []types.CostGroup{{Name: "a", Amount: 50}, {Name: "credit", Amount: -50}}, []float64{100, -100}},
+		{"credits only", []types.CostGroup{{Name: "credit", Amount: -50}}, []float64{math.NaN()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := percentageRows(tt.groups)
+			for i, row := range rows {
+				cell := row[2]
+				if math.IsNaN(tt.want[i]) {
+					if !cell.Blank {
+						t.Errorf("%s: got %.1f%%, want blank", row[0].Text, cell.Value)
+					}
+					continue
+				}
+				if cell.Blank || math.Abs(cell.Value-tt.want[i]) > 0.001 {
+					t.Errorf("%s: got %.1f%% (blank %t), want %.1f%%", row[0].Text, cell.Value, cell.Blank, tt.want[i])
+				}
+			}
+		})
+	}
+}
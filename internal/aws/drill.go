@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// dimensionTitles are the column titles of the dimensions a drill-down can group by
+var dimensionTitles = map[string]string{
+	"SERVICE":        "Service",
+	"REGION":         "Region",
+	"LINKED_ACCOUNT": "Account ID",
+	"USAGE_TYPE":     "Usage Type",
+	"OPERATION":      "Operation",
+}
+
+// GetDrillData fetches costs restricted to the filters of a drill-down path,
+// grouped by dimension with one column per month like the service view
+func GetDrillData(client types.CostExplorerAPI, q types.Query, path []types.DimensionFilter, dimension string) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	now := time.Now()
+	dateRange := historyRange(q, now)
+	period := toInterval(dateRange)
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q, path...),
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeDimension,
+			Key:  &dimension,
+		}},
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	title := dimensionTitles[dimension]
+	if title == "" {
+		title = dimension
+	}
+
+	data := monthlyData(result.ResultsByTime, metric, dateRange, now, title, func(key string) (string, bool) {
+		if dimension == "SERVICE" {
+			return normalizeServiceName(key), true
+		}
+		return key, true
+	})
+
+	var crumbs []string
+	for _, filter := range path {
+		crumbs = append(crumbs, filter.Label)
+	}
+	data.Title = "🔎 " + strings.Join(crumbs, " › ") + " › " + title
+	return data, nil
+}
@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/smithy-go"
+)
+
+// ErrorKind classifies why a Cost Explorer request failed
+type ErrorKind int
+
+const (
+	// ErrorUnknown is any failure that does not match a known kind
+	ErrorUnknown ErrorKind = iota
+	// ErrorTimeout means the request did not finish before its deadline
+	ErrorTimeout
+	// ErrorThrottling means Cost Explorer rejected the request for rate limiting
+	ErrorThrottling
+	// ErrorAccessDenied means the credentials lack the required ce:* permission
+	ErrorAccessDenied
+	// ErrorNotEnabled means Cost Explorer is not enabled or has no data yet
+	ErrorNotEnabled
+	// ErrorExpiredCredentials means the session or SSO token has expired
+	ErrorExpiredCredentials
+	// ErrorHourlyNotEnabled means hourly granularity is not enabled for the account
+	ErrorHourlyNotEnabled
+	// ErrorNoData means Cost Explorer has too little data to answer, e.g. a
+	// forecast for a new account or a narrow filter
+	ErrorNoData
+)
+
+// String returns a short label for the error kind
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrorTimeout:
+		return "Timeout"
+	case ErrorThrottling:
+		return "Throttled"
+	case ErrorAccessDenied:
+		return "Access denied"
+	case ErrorNotEnabled:
+		return "Cost Explorer not enabled"
+	case ErrorExpiredCredentials:
+		return "Credentials expired"
+	case ErrorHourlyNotEnabled:
+		return "Hourly data not enabled"
+	case ErrorNoData:
+		return "No data available"
+	default:
+		return "Error"
+	}
+}
+
+// Hint returns a suggestion for resolving the error kind
+func (k ErrorKind) Hint() string {
+	switch k {
+	case ErrorTimeout:
+		return "the request took longer than 30 seconds"
+	case ErrorThrottling:
+		return "too many requests, wait a moment before retrying"
+	case ErrorAccessDenied:
+		return "grant the ce:* permissions to this identity"
+	case ErrorNotEnabled:
+		return "enable Cost Explorer in the billing console; data can take 24h to appear"
+	case ErrorExpiredCredentials:
+		return "refresh your credentials, e.g. with 'aws sso login'"
+	case ErrorHourlyNotEnabled:
+		return "enable hourly granularity in the Cost Explorer preferences; it covers the last 14 days"
+	case ErrorNoData:
+		return "there is not enough cost history for this request yet"
+	default:
+		return ""
+	}
+}
+
+// FetchError is returned by the data functions when a Cost Explorer call fails
+type FetchError struct {
+	Kind      ErrorKind
+	Operation string
+	Err       error
+}
+
+// Error implements the error interface
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", e.Operation, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// throttlingCodes are the API error codes returned when requests are rate limited
+var throttlingCodes = map[string]bool{
+	"ThrottlingException":      true,
+	"Throttling":               true,
+	"LimitExceededException":   true,
+	"TooManyRequestsException": true,
+	"RequestLimitExceeded":     true,
+}
+
+// expiredCodes are the API error codes returned for expired credentials
+var expiredCodes = map[string]bool{
+	"ExpiredToken":          true,
+	"ExpiredTokenException": true,
+	"RequestExpired":        true,
+}
+
+// classifyError wraps err in a FetchError with the matching kind
+func classifyError(ctx context.Context, operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	kind := errorKind(ctx, err)
+	// Every account with Cost Explorer enabled has cost and usage data, so
+	// only there does its absence mean Cost Explorer is not enabled
+	if kind == ErrorNoData && operation == "GetCostAndUsage" {
+		kind = ErrorNotEnabled
+	}
+	return &FetchError{Kind: kind, Operation: operation, Err: err}
+}
+
+// isDataUnavailable reports whether Cost Explorer has no data for a request,
+// e.g. utilization for an account without Savings Plans
+func isDataUnavailable(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "DataUnavailableException"
+}
+
+// errorKind determines the ErrorKind of a failed request
+func errorKind(ctx context.Context, err error) ErrorKind {
+	if errors.Is(err, context.DeadlineExceeded) || (ctx != nil && ctx.Err() == context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		message := strings.ToLower(apiErr.ErrorMessage())
+		switch {
+		case strings.Contains(message, "hourly") && (code == "ValidationException" || code == "DataUnavailableException"):
+			return ErrorHourlyNotEnabled
+		case throttlingCodes[code]:
+			return ErrorThrottling
+		case expiredCodes[code]:
+			return ErrorExpiredCredentials
+		case code == "DataUnavailableException":
+			return ErrorNoData
+		case code == "AccessDeniedException" && strings.Contains(message, "not enabled"):
+			return ErrorNotEnabled
+		case code == "AccessDeniedException" || code == "UnauthorizedOperation":
+			return ErrorAccessDenied
+		}
+	}
+
+	// Credential providers fail before a request is sent, without an API code
+	message := strings.ToLower(err.Error())
+	if strings.Contains(message, "expired") && (strings.Contains(message, "token") || strings.Contains(message, "credential")) {
+		return ErrorExpiredCredentials
+	}
+
+	return ErrorUnknown
+}
@@ -0,0 +1,119 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestErrorKind(t *testing.T) {
+	apiError := func(code, message string) error {
+		return fmt.Errorf("operation error: %w", &smithy.GenericAPIError{Code: code, Message: message})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"deadline", context.DeadlineExceeded, ErrorTimeout},
+		{"throttling", apiError("ThrottlingException", "Rate exceeded"), ErrorThrottling},
+		{"limit exceeded", apiError("LimitExceededException", "Too many requests"), ErrorThrottling},
+		{"expired token", apiError("ExpiredTokenException", "The security token included in the request is expired"), ErrorExpiredCredentials},
+		{"expired sso session", errors.New("refresh cached SSO token failed: token expired"), ErrorExpiredCredentials},
+		{"access denied", apiError("AccessDeniedException", "User is not authorized to perform ce:GetCostAndUsage"), ErrorAccessDenied},
+		{"not enabled", apiError("AccessDeniedException", "Cost Explorer is not enabled for this account"), ErrorNotEnabled},
+		{"hourly disabled", apiError("ValidationException", "Hourly granularity is not enabled for this account"), ErrorHourlyNotEnabled},
+		{"data unavailable", apiError("DataUnavailableException", "Data is not available"), ErrorNoData},
+		{"other validation", apiError("ValidationException", "Start date is after end date"), ErrorUnknown},
+		{"plain error", errors.New("connection reset"), ErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorKind(context.Background(), tt.err); got != tt.want {
+				t.Errorf("errorKind = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchErrorFromClient(t *testing.T) {
+	client := NewFakeClient()
+	client.Err = &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+
+	_, err := GetServiceData(client, types.Query{})
+
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		t.Fatalf("got %v, want a FetchError", err)
+	}
+	if fetchErr.Kind != ErrorAccessDenied || fetchErr.Operation != "GetCostAndUsage" {
+		t.Errorf("got %s from %s, want %s from GetCostAndUsage", fetchErr.Kind, fetchErr.Operation, ErrorAccessDenied)
+	}
+	if !errors.Is(err, client.Err) {
+		t.Errorf("FetchError does not wrap the client error")
+	}
+}
+
+func TestNoCommitmentsIsNotAnError(t *testing.T) {
+	client := NewFakeClient()
+	client.NoCommitments = true
+
+	for _, mode := range CommitmentModes {
+		data, err := GetSavingsPlansData(client, types.Query{}, mode)
+		if err != nil {
+			t.Fatalf("%s: %v", mode, err)
+		}
+		if data.Notice == "" || len(data.Rows) != 0 {
+			t.Errorf("%s: got %d rows and notice %q, want no rows and a notice", mode, len(data.Rows), data.Notice)
+		}
+	}
+}
+
+func TestClassifyErrorDataUnavailable(t *testing.T) {
+	err := &smithy.GenericAPIError{Code: "DataUnavailableException", Message: "Data is not available"}
+
+	tests := []struct {
+		operation string
+		want      ErrorKind
+	}{
+		{"GetCostAndUsage", ErrorNotEnabled},
+		{"GetCostForecast", ErrorNoData},
+		{"GetSavingsPlansUtilization", ErrorNoData},
+	}
+
+	for _, tt := range tests {
+		var fetchErr *FetchError
+		if !errors.As(classifyError(context.Background(), tt.operation, err), &fetchErr) || fetchErr.Kind != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.operation, fetchErr, tt.want)
+		}
+	}
+}
+
+func TestDashboardWithoutForecast(t *testing.T) {
+	client := NewFakeClient()
+	client.ForecastErr = &smithy.GenericAPIError{Code: "DataUnavailableException", Message: "Insufficient amount of historical data"}
+
+	data, err := GetDashboardData(client, types.Query{})
+	if err != nil {
+		t.Fatalf("GetDashboardData: %v", err)
+	}
+	if len(data.Rows) == 0 {
+		t.Fatal("got no rows, want the current totals")
+	}
+	for _, row := range data.Rows {
+		if row[1].Text == "Forecasted Total" {
+			t.Error("got a forecast row from a failed forecast")
+		}
+	}
+	if want := "No forecast: " + ErrorNoData.String(); !strings.HasPrefix(data.Notice, want) {
+		t.Errorf("notice %q, want it to start with %q", data.Notice, want)
+	}
+}
@@ -0,0 +1,532 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/smithy-go"
+)
+
+// fixtureGroup is a canned group key with its monthly base cost
+type fixtureGroup struct {
+	Key    string
+	Amount float64
+}
+
+// fixtureGroups holds the canned groups returned for each GroupBy dimension
+var fixtureGroups = map[string][]fixtureGroup{
+	"SERVICE": {
+		{"Amazon Elastic Compute Cloud - Compute", 412.50},
+		{"Amazon Relational Database Service", 188.20},
+		{"Amazon Simple Storage Service", 64.10},
+		{"EC2 - Other", 51.75},
+		{"Amazon Elastic Load Balancing", 22.40},
+		{"AWS Lambda", 12.30},
+		{"Amazon Elastic Container Service", 6.20},
+		{"Amazon EC2 Container Service", 1.40},
+		{"Amazon CloudFront", 9.80},
+		{"Amazon Route 53", 2.50},
+		{"Tax", 45.00},
+	},
+	"REGION": {
+		{"us-east-1", 498.60},
+		{"eu-west-1", 201.35},
+		{"us-west-2", 63.10},
+		{"global", 12.30},
+		{"NoRegion", 32.80},
+	},
+	"LINKED_ACCOUNT": {
+		{"111111111111", 402.60},
+		{"222222222222", 231.95},
+		{"333333333333", 129.25},
+		{"444444444444", 44.25},
+	},
+	"USAGE_TYPE": {
+		{"BoxUsage:m5.xlarge", 280.32},
+		{"BoxUsage:t3.medium", 132.18},
+		{"InstanceUsage:db.r5.large", 170.40},
+		{"TimedStorage-ByteHrs", 58.90},
+		{"EBS:VolumeUsage.gp3", 31.20},
+		{"NatGateway-Hours", 20.55},
+		{"DataTransfer-Out-Bytes", 18.75},
+		{"LoadBalancerUsage", 16.20},
+		{"Lambda-GB-Second", 10.10},
+		{"Requests-Tier1", 5.20},
+		{"DNS-Queries", 2.50},
+		{"Tax", 45.00},
+	},
+	"RECORD_TYPE": {
+		{"Usage", 747.65},
+		{"SavingsPlanCoveredUsage", 48.00},
+		{"Tax", 45.00},
+		{"Refund", -12.00},
+		{"Credit", -12.50},
+	},
+	"OPERATION": {
+		{"RunInstances", 412.50},
+		{"CreateDBInstance", 170.40},
+		{"StandardStorage", 58.90},
+		{"NatGateway", 20.55},
+		{"LoadBalancing", 16.20},
+		{"Invoke", 10.10},
+		{"GetObject", 5.20},
+	},
+}
+
+// fixtureAccountNames are the names returned for the LINKED_ACCOUNT fixtures
+var fixtureAccountNames = map[string]string{
+	"111111111111": "Production",
+	"222222222222": "Staging",
+	"333333333333": "Data Platform",
+	"444444444444": "Management",
+}
+
+// fixtureTags holds the canned groups returned when grouping by each tag key.
+// Keys follow the API's "key$value" form; an empty value is untagged spend.
+var fixtureTags = map[string][]fixtureGroup{
+	"team": {
+		{"team$platform", 402.15},
+		{"team$data", 188.20},
+		{"team$web", 96.30},
+		{"team$", 76.95},
+	},
+	"env": {
+		{"env$prod", 521.40},
+		{"env$staging", 118.60},
+		{"env$dev", 64.35},
+		{"env$", 59.25},
+	},
+	"project": {
+		{"project$checkout", 244.80},
+		{"project$search", 131.45},
+		{"project$", 387.35},
+	},
+}
+
+// fixtureCostCategories holds the canned groups returned when grouping by
+// each cost category, keyed "name$value" like tags
+var fixtureCostCategories = map[string][]fixtureGroup{
+	"Business Unit": {
+		{"Business Unit$Retail", 356.70},
+		{"Business Unit$Wholesale", 241.25},
+		{"Business Unit$Shared", 151.05},
+		{"Business Unit$", 14.40},
+	},
+	"Cost Center": {
+		{"Cost Center$CC-100 Engineering", 498.10},
+		{"Cost Center$CC-200 Analytics", 212.85},
+		{"Cost Center$", 52.45},
+	},
+}
+
+// FakeClient is an in-memory types.CostExplorerAPI that synthesizes
+// deterministic responses from canned fixture data. It lets the data
+// functions and the TUI run without AWS credentials.
+type FakeClient struct {
+	// Err, when set, is returned by every call instead of canned data
+	Err error
+	// ForecastErr, when set, is returned by GetCostForecast alone, like an
+	// account with too little history to forecast
+	ForecastErr error
+	// HourlyDisabled makes HOURLY requests fail like an account without
+	// hourly granularity enabled
+	HourlyDisabled bool
+	// NoCommitments makes Savings Plans and reservation requests fail like an
+	// account without any
+	NoCommitments bool
+	// PageSize, when positive, splits GetCostAndUsage groups across pages of
+	// at most this many groups, linked by NextPageToken like the real API
+	PageSize int
+
+	mu       sync.Mutex
+	calls    map[string]int
+	feedback map[string]awstypes.AnomalyFeedbackType // Given with ProvideAnomalyFeedback
+}
+
+// NewFakeClient creates a FakeClient serving the built-in fixtures
+func NewFakeClient() *FakeClient {
+	return &FakeClient{calls: make(map[string]int)}
+}
+
+// Calls returns how many times the named operation has been invoked
+func (f *FakeClient) Calls(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[operation]
+}
+
+// record counts an invocation of the named operation
+func (f *FakeClient) record(operation string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[operation]++
+}
+
+// GetCostAndUsage returns one result per period in the requested interval,
+// grouped by the first GroupBy key when present
+func (f *FakeClient) GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error) {
+	f.record("GetCostAndUsage")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.HourlyDisabled && params.Granularity == awstypes.GranularityHourly {
+		return nil, &smithy.GenericAPIError{
+			Code:    "ValidationException",
+			Message: "Hourly granularity is not enabled for this account",
+		}
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, params.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	groupKey := ""
+	fixtures := fixtureGroups
+	if len(params.GroupBy) > 0 && params.GroupBy[0].Key != nil {
+		groupKey = *params.GroupBy[0].Key
+		switch params.GroupBy[0].Type {
+		case awstypes.GroupDefinitionTypeTag:
+			fixtures = fixtureTags
+		case awstypes.GroupDefinitionTypeCostCategory:
+			fixtures = fixtureCostCategories
+		}
+	}
+
+	output := &costexplorer.GetCostAndUsageOutput{}
+	for i, period := range periods {
+		scale := fixtureScale(params.Granularity, i, period) * fixtureFilterShare(params.Filter)
+		result := awstypes.ResultByTime{
+			TimePeriod: &awstypes.DateInterval{
+				Start: aws.String(period.Start),
+				End:   aws.String(period.End),
+			},
+			Estimated: i == len(periods)-1,
+		}
+
+		if groupKey == "" {
+			var total float64
+			for _, group := range fixtureGroups["SERVICE"] {
+				total += group.Amount * scale
+			}
+			result.Total = fixtureMetrics(params.Metrics, total)
+		} else {
+			for j, group := range fixtures[groupKey] {
+				// Vary each group a little per period so month-over-month columns differ
+				amount := group.Amount * scale * (1 + 0.04*float64((i+j)%5-2))
+				result.Groups = append(result.Groups, awstypes.Group{
+					Keys:    []string{group.Key},
+					Metrics: fixtureMetrics(params.Metrics, amount),
+				})
+			}
+		}
+
+		output.ResultsByTime = append(output.ResultsByTime, result)
+	}
+
+	if f.PageSize > 0 {
+		return fixturePage(output, f.PageSize, params.NextPageToken)
+	}
+	return output, nil
+}
+
+// fixturePage returns the page of groups selected by token. Every page repeats
+// the periods its groups belong to, and ungrouped periods appear on the first page.
+func fixturePage(full *costexplorer.GetCostAndUsageOutput, pageSize int, token *string) (*costexplorer.GetCostAndUsageOutput, error) {
+	page := 0
+	if token != nil {
+		parsed, err := strconv.Atoi(*token)
+		if err != nil {
+			return nil, fmt.Errorf("fake: invalid NextPageToken %q", *token)
+		}
+		page = parsed
+	}
+
+	first, last := page*pageSize, (page+1)*pageSize
+	output := &costexplorer.GetCostAndUsageOutput{}
+	index := 0
+	for _, result := range full.ResultsByTime {
+		if len(result.Groups) == 0 {
+			if page == 0 {
+				output.ResultsByTime = append(output.ResultsByTime, result)
+			}
+			continue
+		}
+
+		var groups []awstypes.Group
+		for _, group := range result.Groups {
+			if index >= first && index < last {
+				groups = append(groups, group)
+			}
+			index++
+		}
+		if len(groups) > 0 {
+			result.Groups = groups
+			output.ResultsByTime = append(output.ResultsByTime, result)
+		}
+	}
+
+	if index > last {
+		output.NextPageToken = aws.String(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+// GetCostForecast returns a slowly growing forecast based on the fixture
+// service totals
+func (f *FakeClient) GetCostForecast(ctx context.Context, params *costexplorer.GetCostForecastInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostForecastOutput, error) {
+	f.record("GetCostForecast")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.ForecastErr != nil {
+		return nil, f.ForecastErr
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, params.Granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	var monthly float64
+	for _, group := range fixtureGroups["SERVICE"] {
+		monthly += group.Amount
+	}
+
+	output := &costexplorer.GetCostForecastOutput{}
+	var total float64
+	for i, period := range periods {
+		// Grow a little each period so comparisons with the past differ
+		mean := monthly * fixtureScale(params.Granularity, i, period) * fixtureFilterShare(params.Filter) * (1 + 0.02*float64(i+1))
+		total += mean
+		output.ForecastResultsByTime = append(output.ForecastResultsByTime, awstypes.ForecastResult{
+			TimePeriod: &awstypes.DateInterval{
+				Start: aws.String(period.Start),
+				End:   aws.String(period.End),
+			},
+			MeanValue: aws.String(strconv.FormatFloat(mean, 'f', 10, 64)),
+		})
+
+		// Bounds widen with the requested confidence
+		if params.PredictionIntervalLevel != nil {
+			spread := float64(*params.PredictionIntervalLevel) / 800
+			result := &output.ForecastResultsByTime[len(output.ForecastResultsByTime)-1]
+			result.PredictionIntervalLowerBound = aws.String(strconv.FormatFloat(mean*(1-spread), 'f', 10, 64))
+			result.PredictionIntervalUpperBound = aws.String(strconv.FormatFloat(mean*(1+spread), 'f', 10, 64))
+		}
+	}
+	output.Total = &awstypes.MetricValue{
+		Amount: aws.String(strconv.FormatFloat(total, 'f', 10, 64)),
+		Unit:   aws.String("USD"),
+	}
+
+	return output, nil
+}
+
+// GetDimensionValues returns the fixture keys of the requested dimension,
+// with account names as the description of LINKED_ACCOUNT values
+func (f *FakeClient) GetDimensionValues(ctx context.Context, params *costexplorer.GetDimensionValuesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetDimensionValuesOutput, error) {
+	f.record("GetDimensionValues")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	output := &costexplorer.GetDimensionValuesOutput{}
+	for _, group := range fixtureGroups[string(params.Dimension)] {
+		value := awstypes.DimensionValuesWithAttributes{Value: aws.String(group.Key)}
+		if name, exists := fixtureAccountNames[group.Key]; exists {
+			value.Attributes = map[string]string{"description": name}
+		}
+		output.DimensionValues = append(output.DimensionValues, value)
+	}
+	output.ReturnSize = aws.Int32(int32(len(output.DimensionValues)))
+	output.TotalSize = output.ReturnSize
+
+	return output, nil
+}
+
+// GetTags returns the fixture tag keys, or the values of TagKey when set
+func (f *FakeClient) GetTags(ctx context.Context, params *costexplorer.GetTagsInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetTagsOutput, error) {
+	f.record("GetTags")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	output := &costexplorer.GetTagsOutput{}
+	if params.TagKey != nil {
+		for _, group := range fixtureTags[*params.TagKey] {
+			if value := groupValue(group.Key, ""); value != "" {
+				output.Tags = append(output.Tags, value)
+			}
+		}
+	} else {
+		for key := range fixtureTags {
+			output.Tags = append(output.Tags, key)
+		}
+		sort.Strings(output.Tags)
+	}
+	output.ReturnSize = aws.Int32(int32(len(output.Tags)))
+	output.TotalSize = output.ReturnSize
+
+	return output, nil
+}
+
+// GetCostCategories returns the fixture cost category names, or the values
+// of CostCategoryName when set
+func (f *FakeClient) GetCostCategories(ctx context.Context, params *costexplorer.GetCostCategoriesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostCategoriesOutput, error) {
+	f.record("GetCostCategories")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	output := &costexplorer.GetCostCategoriesOutput{}
+	if params.CostCategoryName != nil {
+		for _, group := range fixtureCostCategories[*params.CostCategoryName] {
+			if value := groupValue(group.Key, ""); value != "" {
+				output.CostCategoryValues = append(output.CostCategoryValues, value)
+			}
+		}
+		output.ReturnSize = aws.Int32(int32(len(output.CostCategoryValues)))
+	} else {
+		for name := range fixtureCostCategories {
+			output.CostCategoryNames = append(output.CostCategoryNames, name)
+		}
+		sort.Strings(output.CostCategoryNames)
+		output.ReturnSize = aws.Int32(int32(len(output.CostCategoryNames)))
+	}
+	output.TotalSize = output.ReturnSize
+
+	return output, nil
+}
+
+// fixturePeriod is one [Start, End) slice of a requested interval; Fraction is
+// the share of a full month (or day) that it covers
+type fixturePeriod struct {
+	Start    string
+	End      string
+	Fraction float64
+}
+
+// fixturePeriods splits an interval into periods by granularity
+func fixturePeriods(interval *awstypes.DateInterval, granularity awstypes.Granularity) ([]fixturePeriod, error) {
+	if interval == nil || interval.Start == nil || interval.End == nil {
+		return nil, fmt.Errorf("fake: TimePeriod is required")
+	}
+
+	// Hourly requests use full timestamps, the others plain dates
+	layout := "2006-01-02"
+	if granularity == awstypes.GranularityHourly {
+		layout = time.RFC3339
+	}
+
+	start, err := time.Parse(layout, *interval.Start)
+	if err != nil {
+		return nil, fmt.Errorf("fake: invalid start date: %w", err)
+	}
+	end, err := time.Parse(layout, *interval.End)
+	if err != nil {
+		return nil, fmt.Errorf("fake: invalid end date: %w", err)
+	}
+
+	var periods []fixturePeriod
+	for cursor := start; cursor.Before(end); {
+		var next time.Time
+		var full time.Duration
+		switch granularity {
+		case awstypes.GranularityHourly:
+			next = cursor.Add(time.Hour)
+			full = time.Hour
+		case awstypes.GranularityDaily:
+			next = cursor.AddDate(0, 0, 1)
+			full = next.Sub(cursor)
+		default:
+			monthStart := time.Date(cursor.Year(), cursor.Month(), 1, 0, 0, 0, 0, time.UTC)
+			next = monthStart.AddDate(0, 1, 0)
+			full = next.Sub(monthStart)
+		}
+		if next.After(end) {
+			next = end
+		}
+		periods = append(periods, fixturePeriod{
+			Start:    cursor.Format(layout),
+			End:      next.Format(layout),
+			Fraction: float64(next.Sub(cursor)) / float64(full),
+		})
+		cursor = next
+	}
+
+	return periods, nil
+}
+
+// fixtureScale converts a monthly fixture amount to the given period
+func fixtureScale(granularity awstypes.Granularity, index int, period fixturePeriod) float64 {
+	switch granularity {
+	case awstypes.GranularityHourly:
+		// Busier during the day than at night
+		return period.Fraction * (1 + 0.3*float64(index%24/12*2-1)) / 720
+	case awstypes.GranularityDaily:
+		// Small weekly wave so daily figures are not perfectly flat
+		return period.Fraction * (1 + 0.1*float64(index%7-3)) / 30
+	}
+	return period.Fraction
+}
+
+// fixtureFilterShare shrinks amounts for filtered requests so that a filter
+// or drill-down shows a plausible part of the unfiltered cost
+func fixtureFilterShare(filter *awstypes.Expression) float64 {
+	switch {
+	case filter == nil:
+		return 1
+	case filter.Dimensions != nil, filter.Tags != nil, filter.CostCategories != nil:
+		return 0.4
+	case filter.Not != nil:
+		return 1 - fixtureFilterShare(filter.Not)
+	case len(filter.Or) > 0:
+		share := 0.0
+		for _, expression := range filter.Or {
+			share += fixtureFilterShare(&expression)
+		}
+		return min(share, 1)
+	}
+
+	share := 1.0
+	for _, expression := range filter.And {
+		share *= fixtureFilterShare(&expression)
+	}
+	return share
+}
+
+// fixtureAmount formats an amount the way the API returns numbers
+func fixtureAmount(amount float64) *string {
+	return aws.String(strconv.FormatFloat(amount, 'f', 10, 64))
+}
+
+// fixtureMetrics builds a metric map with the same amount for every requested metric
+func fixtureMetrics(metrics []string, amount float64) map[string]awstypes.MetricValue {
+	values := make(map[string]awstypes.MetricValue, len(metrics))
+	for _, metric := range metrics {
+		values[metric] = awstypes.MetricValue{
+			Amount: fixtureAmount(amount),
+			Unit:   aws.String("USD"),
+		}
+	}
+	return values
+}
+
+// Ensure both the real and the fake client satisfy the interface
+var (
+	_ types.CostExplorerAPI = (*costexplorer.Client)(nil)
+	_ types.CostExplorerAPI = (*FakeClient)(nil)
+)
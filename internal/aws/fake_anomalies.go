@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fixtureMonitors holds the canned anomaly monitors by ARN
+var fixtureMonitors = map[string]string{
+	"arn:aws:ce::111111111111:anomalymonitor/services": "AWS services",
+	"arn:aws:ce::111111111111:anomalymonitor/accounts": "Member accounts",
+}
+
+// fixtureAnomaly is a canned anomaly that started DaysAgo days before today
+// and lasted Days days, or is ongoing when Days is zero
+type fixtureAnomaly struct {
+	ID, Monitor   string
+	DaysAgo, Days int
+	Impact        float64
+	Expected      float64
+	Causes        []awstypes.RootCause
+	Feedback      awstypes.AnomalyFeedbackType
+}
+
+// fixtureAnomalies holds the canned anomalies
+var fixtureAnomalies = []fixtureAnomaly{
+	{"anomaly-ec2-spike", "arn:aws:ce::111111111111:anomalymonitor/services", 2, 0, 312.40, 95.00,
+		[]awstypes.RootCause{
+			fixtureRootCause("Amazon Elastic Compute Cloud - Compute", "222222222222", "Staging", "us-west-2", "USW2-BoxUsage:c5.4xlarge", 268.10),
+			fixtureRootCause("Amazon Elastic Compute Cloud - Compute", "222222222222", "Staging", "us-west-2", "USW2-EBS:VolumeUsage.gp3", 44.30),
+		}, ""},
+	{"anomaly-s3-requests", "arn:aws:ce::111111111111:anomalymonitor/services", 11, 3, 87.25, 40.10,
+		[]awstypes.RootCause{
+			fixtureRootCause("Amazon Simple Storage Service", "111111111111", "Production", "us-east-1", "Requests-Tier1", 87.25),
+		}, ""},
+	{"anomaly-data-transfer", "arn:aws:ce::111111111111:anomalymonitor/accounts", 45, 5, 154.80, 60.00,
+		[]awstypes.RootCause{
+			fixtureRootCause("AWS Data Transfer", "333333333333", "Analytics", "eu-west-1", "EU-DataTransfer-Out-Bytes", 154.80),
+		}, awstypes.AnomalyFeedbackTypePlannedActivity},
+}
+
+// fixtureRootCause builds a root cause contributing impact
+func fixtureRootCause(service, account, accountName, region, usageType string, impact float64) awstypes.RootCause {
+	return awstypes.RootCause{
+		Service:           aws.String(service),
+		LinkedAccount:     aws.String(account),
+		LinkedAccountName: aws.String(accountName),
+		Region:            aws.String(region),
+		UsageType:         aws.String(usageType),
+		Impact:            &awstypes.RootCauseImpact{Contribution: impact},
+	}
+}
+
+// GetAnomalyMonitors returns the fixture monitors
+func (f *FakeClient) GetAnomalyMonitors(ctx context.Context, params *costexplorer.GetAnomalyMonitorsInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetAnomalyMonitorsOutput, error) {
+	f.record("GetAnomalyMonitors")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	output := &costexplorer.GetAnomalyMonitorsOutput{}
+	for arn, name := range fixtureMonitors {
+		output.AnomalyMonitors = append(output.AnomalyMonitors, awstypes.AnomalyMonitor{
+			MonitorArn:  aws.String(arn),
+			MonitorName: aws.String(name),
+		})
+	}
+	return output, nil
+}
+
+// GetAnomalies returns the fixture anomalies that started within the
+// requested interval, with any feedback given since
+func (f *FakeClient) GetAnomalies(ctx context.Context, params *costexplorer.GetAnomaliesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetAnomaliesOutput, error) {
+	f.record("GetAnomalies")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if params.DateInterval == nil {
+		return nil, fmt.Errorf("DateInterval is required")
+	}
+	start, err := time.Parse("2006-01-02", aws.ToString(params.DateInterval.StartDate))
+	if err != nil {
+		return nil, fmt.Errorf("invalid StartDate: %w", err)
+	}
+	end := today(time.Now())
+	if params.DateInterval.EndDate != nil {
+		if end, err = time.Parse("2006-01-02", *params.DateInterval.EndDate); err != nil {
+			return nil, fmt.Errorf("invalid EndDate: %w", err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	output := &costexplorer.GetAnomaliesOutput{}
+	for _, fixture := range fixtureAnomalies {
+		started := today(time.Now()).AddDate(0, 0, -fixture.DaysAgo)
+		if started.Before(start) || started.After(end) {
+			continue
+		}
+
+		anomaly := awstypes.Anomaly{
+			AnomalyId:        aws.String(fixture.ID),
+			MonitorArn:       aws.String(fixture.Monitor),
+			AnomalyStartDate: aws.String(started.Format("2006-01-02")),
+			DimensionValue:   fixture.Causes[0].Service,
+			RootCauses:       fixture.Causes,
+			Feedback:         fixture.Feedback,
+			AnomalyScore:     &awstypes.AnomalyScore{CurrentScore: 0.4, MaxScore: 0.9},
+			Impact: &awstypes.Impact{
+				TotalImpact:           fixture.Impact,
+				MaxImpact:             fixture.Impact / float64(max(fixture.Days, 1)),
+				TotalActualSpend:      aws.Float64(fixture.Expected + fixture.Impact),
+				TotalExpectedSpend:    aws.Float64(fixture.Expected),
+				TotalImpactPercentage: aws.Float64(percentOf(fixture.Impact, fixture.Expected)),
+			},
+		}
+		if fixture.Days > 0 {
+			anomaly.AnomalyEndDate = aws.String(started.AddDate(0, 0, fixture.Days-1).Format("2006-01-02"))
+		}
+		if feedback, exists := f.feedback[fixture.ID]; exists {
+			anomaly.Feedback = feedback
+		}
+		output.Anomalies = append(output.Anomalies, anomaly)
+	}
+	return output, nil
+}
+
+// ProvideAnomalyFeedback records feedback for a fixture anomaly
+func (f *FakeClient) ProvideAnomalyFeedback(ctx context.Context, params *costexplorer.ProvideAnomalyFeedbackInput, optFns ...func(*costexplorer.Options)) (*costexplorer.ProvideAnomalyFeedbackOutput, error) {
+	f.record("ProvideAnomalyFeedback")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	id := aws.ToString(params.AnomalyId)
+	known := false
+	for _, fixture := range fixtureAnomalies {
+		known = known || fixture.ID == id
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown anomaly %q", id)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.feedback == nil {
+		f.feedback = make(map[string]awstypes.AnomalyFeedbackType)
+	}
+	f.feedback[id] = params.Feedback
+	return &costexplorer.ProvideAnomalyFeedbackOutput{AnomalyId: params.AnomalyId}, nil
+}
@@ -0,0 +1,283 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+	"github.com/aws/smithy-go"
+)
+
+// fixtureCommitment is a canned coverage group: its monthly eligible spend,
+// or running hours for reservations, and the share covered by commitments
+type fixtureCommitment struct {
+	Attributes map[string]string
+	Spend      float64
+	Coverage   float64
+}
+
+// fixtureSavingsPlansCoverage holds the canned Savings Plans coverage groups
+var fixtureSavingsPlansCoverage = []fixtureCommitment{
+	{map[string]string{"SERVICE": "Amazon Elastic Compute Cloud - Compute", "INSTANCE_FAMILY": "m5"}, 280.32, 0.86},
+	{map[string]string{"SERVICE": "Amazon Elastic Compute Cloud - Compute", "INSTANCE_FAMILY": "t3"}, 132.18, 0.58},
+	{map[string]string{"SERVICE": "AWS Lambda", "INSTANCE_FAMILY": ""}, 12.30, 0.35},
+	{map[string]string{"SERVICE": "Amazon Elastic Container Service", "INSTANCE_FAMILY": ""}, 41.60, 0.12},
+}
+
+// fixtureUtilization is the Savings Plans utilization of successive months
+var fixtureUtilization = []float64{0.96, 0.88, 0.74, 0.63}
+
+// fixtureCommitmentMonthly is the monthly Savings Plans commitment
+const fixtureCommitmentMonthly = 300.0
+
+// errNoCommitments is returned when NoCommitments is set
+var errNoCommitments = &smithy.GenericAPIError{
+	Code:    "DataUnavailableException",
+	Message: "No commitment data is available for this account",
+}
+
+// GetSavingsPlansUtilization returns one result per month of the fixture
+// commitment, with utilization varying by month
+func (f *FakeClient) GetSavingsPlansUtilization(ctx context.Context, params *costexplorer.GetSavingsPlansUtilizationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansUtilizationOutput, error) {
+	f.record("GetSavingsPlansUtilization")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, awstypes.GranularityMonthly)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &costexplorer.GetSavingsPlansUtilizationOutput{}
+	var commitment, used float64
+	for i, period := range periods {
+		periodCommitment := fixtureCommitmentMonthly * period.Fraction
+		periodUsed := periodCommitment * fixtureUtilization[i%len(fixtureUtilization)]
+		commitment += periodCommitment
+		used += periodUsed
+
+		output.SavingsPlansUtilizationsByTime = append(output.SavingsPlansUtilizationsByTime, awstypes.SavingsPlansUtilizationByTime{
+			TimePeriod: &awstypes.DateInterval{
+				Start: aws.String(period.Start),
+				End:   aws.String(period.End),
+			},
+			Utilization: fixtureSavingsPlansUtilization(periodCommitment, periodUsed),
+			Savings:     fixtureSavingsPlansSavings(periodCommitment, periodUsed),
+		})
+	}
+	output.Total = &awstypes.SavingsPlansUtilizationAggregates{
+		Utilization: fixtureSavingsPlansUtilization(commitment, used),
+		Savings:     fixtureSavingsPlansSavings(commitment, used),
+	}
+
+	return output, nil
+}
+
+// fixtureSavingsPlansUtilization describes a commitment of which used was used
+func fixtureSavingsPlansUtilization(commitment, used float64) *awstypes.SavingsPlansUtilization {
+	return &awstypes.SavingsPlansUtilization{
+		TotalCommitment:       fixtureAmount(commitment),
+		UsedCommitment:        fixtureAmount(used),
+		UnusedCommitment:      fixtureAmount(commitment - used),
+		UtilizationPercentage: fixtureAmount(percentOf(used, commitment)),
+	}
+}
+
+// fixtureSavingsPlansSavings assumes used commitment replaces on-demand spend
+// at a 28% discount, less the unused commitment
+func fixtureSavingsPlansSavings(commitment, used float64) *awstypes.SavingsPlansSavings {
+	onDemand := used / 0.72
+	return &awstypes.SavingsPlansSavings{
+		NetSavings:             fixtureAmount(onDemand - commitment),
+		OnDemandCostEquivalent: fixtureAmount(onDemand),
+	}
+}
+
+// GetSavingsPlansCoverage returns the fixture coverage groups for each month
+func (f *FakeClient) GetSavingsPlansCoverage(ctx context.Context, params *costexplorer.GetSavingsPlansCoverageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansCoverageOutput, error) {
+	f.record("GetSavingsPlansCoverage")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, awstypes.GranularityMonthly)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &costexplorer.GetSavingsPlansCoverageOutput{}
+	for _, period := range periods {
+		for _, group := range fixtureSavingsPlansCoverage {
+			total := group.Spend * period.Fraction
+			covered := total * group.Coverage
+			output.SavingsPlansCoverages = append(output.SavingsPlansCoverages, awstypes.SavingsPlansCoverage{
+				Attributes: group.Attributes,
+				TimePeriod: &awstypes.DateInterval{
+					Start: aws.String(period.Start),
+					End:   aws.String(period.End),
+				},
+				Coverage: &awstypes.SavingsPlansCoverageData{
+					CoveragePercentage:         fixtureAmount(group.Coverage * 100),
+					SpendCoveredBySavingsPlans: fixtureAmount(covered),
+					OnDemandCost:               fixtureAmount(total - covered),
+					TotalCost:                  fixtureAmount(total),
+				},
+			})
+		}
+	}
+
+	return output, nil
+}
+
+// fixtureSubscription is a canned Reserved Instance subscription with its
+// monthly purchased hours and utilization
+type fixtureSubscription struct {
+	ID, InstanceType, Region string
+	Hours, Utilization, Rate float64
+}
+
+// fixtureSubscriptions holds the canned Reserved Instance subscriptions
+var fixtureSubscriptions = []fixtureSubscription{
+	{"1234567890", "m5.xlarge", "us-east-1", 1460, 0.99, 0.121},
+	{"2345678901", "db.r5.large", "eu-west-1", 730, 0.82, 0.155},
+	{"3456789012", "t3.medium", "us-west-2", 2190, 0.41, 0.026},
+}
+
+// fixtureReservationCoverage holds the canned coverage per service and
+// region: monthly running hours and the share covered by reservations
+var fixtureReservationCoverage = map[string][]fixtureCommitment{
+	"Amazon Elastic Compute Cloud - Compute": {
+		{map[string]string{"region": "us-east-1"}, 4380, 0.33},
+		{map[string]string{"region": "us-west-2"}, 2920, 0.75},
+	},
+	"Amazon Relational Database Service": {
+		{map[string]string{"region": "eu-west-1"}, 1460, 0.5},
+	},
+}
+
+// GetReservationUtilization returns the fixture subscriptions as groups of a
+// single result covering the whole period
+func (f *FakeClient) GetReservationUtilization(ctx context.Context, params *costexplorer.GetReservationUtilizationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationUtilizationOutput, error) {
+	f.record("GetReservationUtilization")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, awstypes.GranularityMonthly)
+	if err != nil {
+		return nil, err
+	}
+	var months float64
+	for _, period := range periods {
+		months += period.Fraction
+	}
+
+	result := awstypes.UtilizationByTime{TimePeriod: params.TimePeriod}
+	var purchased, used, unusedCost, savings float64
+	for _, subscription := range fixtureSubscriptions {
+		hours := subscription.Hours * months
+		usedHours := hours * subscription.Utilization
+		purchased += hours
+		used += usedHours
+		unusedCost += (hours - usedHours) * subscription.Rate
+		savings += usedHours*subscription.Rate*0.4 - (hours-usedHours)*subscription.Rate
+
+		result.Groups = append(result.Groups, awstypes.ReservationUtilizationGroup{
+			Key:   aws.String("SUBSCRIPTION_ID"),
+			Value: aws.String(subscription.ID),
+			Attributes: map[string]string{
+				"instanceType": subscription.InstanceType,
+				"region":       subscription.Region,
+			},
+			Utilization: fixtureReservationAggregates(hours, usedHours, subscription.Rate),
+		})
+	}
+
+	total := fixtureReservationAggregates(purchased, used, 0)
+	total.RICostForUnusedHours = fixtureAmount(unusedCost)
+	total.NetRISavings = fixtureAmount(savings)
+	result.Total = total
+
+	return &costexplorer.GetReservationUtilizationOutput{
+		UtilizationsByTime: []awstypes.UtilizationByTime{result},
+		Total:              total,
+	}, nil
+}
+
+// fixtureReservationAggregates describes purchased hours of which used were
+// used, assuming reservations save 40% of the on-demand rate
+func fixtureReservationAggregates(purchased, used, rate float64) *awstypes.ReservationAggregates {
+	unused := purchased - used
+	return &awstypes.ReservationAggregates{
+		PurchasedHours:        fixtureAmount(purchased),
+		TotalActualHours:      fixtureAmount(used),
+		UnusedHours:           fixtureAmount(unused),
+		UtilizationPercentage: fixtureAmount(percentOf(used, purchased)),
+		RICostForUnusedHours:  fixtureAmount(unused * rate),
+		NetRISavings:          fixtureAmount(used*rate*0.4 - unused*rate),
+	}
+}
+
+// GetReservationCoverage returns the fixture coverage of the service in the
+// SERVICE filter, EC2 when there is none, grouped by region for each month
+func (f *FakeClient) GetReservationCoverage(ctx context.Context, params *costexplorer.GetReservationCoverageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationCoverageOutput, error) {
+	f.record("GetReservationCoverage")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	periods, err := fixturePeriods(params.TimePeriod, awstypes.GranularityMonthly)
+	if err != nil {
+		return nil, err
+	}
+
+	service := "Amazon Elastic Compute Cloud - Compute"
+	if params.Filter != nil && params.Filter.Dimensions != nil && len(params.Filter.Dimensions.Values) > 0 {
+		service = params.Filter.Dimensions.Values[0]
+	}
+
+	output := &costexplorer.GetReservationCoverageOutput{}
+	for _, period := range periods {
+		result := awstypes.CoverageByTime{
+			TimePeriod: &awstypes.DateInterval{
+				Start: aws.String(period.Start),
+				End:   aws.String(period.End),
+			},
+		}
+		for _, group := range fixtureReservationCoverage[service] {
+			total := group.Spend * period.Fraction
+			reserved := total * group.Coverage
+			result.Groups = append(result.Groups, awstypes.ReservationCoverageGroup{
+				Attributes: group.Attributes,
+				Coverage: &awstypes.Coverage{
+					CoverageHours: &awstypes.CoverageHours{
+						CoverageHoursPercentage: fixtureAmount(group.Coverage * 100),
+						ReservedHours:           fixtureAmount(reserved),
+						OnDemandHours:           fixtureAmount(total - reserved),
+						TotalRunningHours:       fixtureAmount(total),
+					},
+					CoverageCost: &awstypes.CoverageCost{
+						OnDemandCost: fixtureAmount((total - reserved) * 0.1),
+					},
+				},
+			})
+		}
+		output.CoveragesByTime = append(output.CoveragesByTime, result)
+	}
+
+	return output, nil
+}
@@ -0,0 +1,358 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// fixtureInstance is a canned EC2 instance with a rightsizing recommendation.
+// Instances without targets are recommended for termination; Memory is
+// negative for instances without the CloudWatch agent.
+type fixtureInstance struct {
+	ID, Name, Account, Region, InstanceType string
+	MonthlyCost, CPU, Memory                float64
+	Targets                                 []fixtureTarget
+	Findings                                []awstypes.FindingReasonCode
+}
+
+// fixtureTarget is a canned target instance type and its monthly cost
+type fixtureTarget struct {
+	InstanceType string
+	MonthlyCost  float64
+}
+
+// fixtureInstances holds the canned rightsizing recommendations
+var fixtureInstances = []fixtureInstance{
+	{"i-0a1b2c3d4e5f60718", "web-1", "111111111111", "us-east-1", "m5.2xlarge", 280.32, 18.5, 31.2,
+		[]fixtureTarget{{"m5.xlarge", 140.16}, {"m5.large", 70.08}},
+		[]awstypes.FindingReasonCode{awstypes.FindingReasonCodeCpuOverProvisioned, awstypes.FindingReasonCodeMemoryOverProvisioned}},
+	{"i-0b2c3d4e5f6071829", "batch-worker", "222222222222", "us-west-2", "c5.4xlarge", 496.40, 9.1, -1,
+		[]fixtureTarget{{"c5.2xlarge", 248.20}},
+		[]awstypes.FindingReasonCode{awstypes.FindingReasonCodeCpuOverProvisioned}},
+	{"i-0c3d4e5f607182930", "old-bastion", "111111111111", "eu-west-1", "t3.large", 60.74, 0.4, 4.8,
+		nil, nil},
+	{"i-0d4e5f60718293041", "reporting", "333333333333", "us-east-1", "r5.xlarge", 183.96, 42.0, 38.5,
+		[]fixtureTarget{{"r5.large", 91.98}},
+		[]awstypes.FindingReasonCode{awstypes.FindingReasonCodeMemoryOverProvisioned}},
+}
+
+// GetRightsizingRecommendation returns the fixture recommendations, split
+// across pages of PageSize like GetCostAndUsage
+func (f *FakeClient) GetRightsizingRecommendation(ctx context.Context, params *costexplorer.GetRightsizingRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetRightsizingRecommendationOutput, error) {
+	f.record("GetRightsizingRecommendation")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	var recommendations []awstypes.RightsizingRecommendation
+	var totalCost, totalSavings float64
+	for _, instance := range fixtureInstances {
+		recommendation := fixtureRightsizing(instance)
+		recommendations = append(recommendations, recommendation)
+		totalCost += instance.MonthlyCost
+		totalSavings += instance.MonthlyCost
+		if len(instance.Targets) > 0 {
+			totalSavings -= instance.Targets[0].MonthlyCost
+		}
+	}
+
+	start := 0
+	if params.NextPageToken != nil {
+		var err error
+		if start, err = strconv.Atoi(*params.NextPageToken); err != nil {
+			return nil, fmt.Errorf("invalid page token %q", *params.NextPageToken)
+		}
+	}
+	end := len(recommendations)
+	if f.PageSize > 0 && start+f.PageSize < end {
+		end = start + f.PageSize
+	}
+	if start > end {
+		start = end
+	}
+
+	output := &costexplorer.GetRightsizingRecommendationOutput{
+		RightsizingRecommendations: recommendations[start:end],
+		Summary: &awstypes.RightsizingRecommendationSummary{
+			EstimatedTotalMonthlySavingsAmount: fixtureAmount(totalSavings),
+			SavingsCurrencyCode:                aws.String("USD"),
+			SavingsPercentage:                  fixtureAmount(percentOf(totalSavings, totalCost)),
+			TotalRecommendationCount:           aws.String(strconv.Itoa(len(recommendations))),
+		},
+	}
+	if end < len(recommendations) {
+		output.NextPageToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+// fixtureRightsizing builds the recommendation for a fixture instance; its
+// first target is the default
+func fixtureRightsizing(instance fixtureInstance) awstypes.RightsizingRecommendation {
+	utilization := &awstypes.EC2ResourceUtilization{MaxCpuUtilizationPercentage: fixtureAmount(instance.CPU)}
+	if instance.Memory >= 0 {
+		utilization.MaxMemoryUtilizationPercentage = fixtureAmount(instance.Memory)
+	}
+
+	recommendation := awstypes.RightsizingRecommendation{
+		AccountId:          aws.String(instance.Account),
+		FindingReasonCodes: instance.Findings,
+		CurrentInstance: &awstypes.CurrentInstance{
+			ResourceId:   aws.String(instance.ID),
+			InstanceName: aws.String(instance.Name),
+			CurrencyCode: aws.String("USD"),
+			MonthlyCost:  fixtureAmount(instance.MonthlyCost),
+			ResourceDetails: &awstypes.ResourceDetails{EC2ResourceDetails: &awstypes.EC2ResourceDetails{
+				InstanceType: aws.String(instance.InstanceType),
+				Region:       aws.String(instance.Region),
+				Platform:     aws.String("Linux/UNIX"),
+			}},
+			ResourceUtilization:               &awstypes.ResourceUtilization{EC2ResourceUtilization: utilization},
+			TotalRunningHoursInLookbackPeriod: fixtureAmount(336),
+			OnDemandHoursInLookbackPeriod:     fixtureAmount(336),
+			Tags: []awstypes.TagValues{
+				{Key: aws.String("Name"), Values: []string{instance.Name}},
+			},
+		},
+	}
+
+	if len(instance.Targets) == 0 {
+		recommendation.RightsizingType = awstypes.RightsizingTypeTerminate
+		recommendation.TerminateRecommendationDetail = &awstypes.TerminateRecommendationDetail{
+			CurrencyCode:            aws.String("USD"),
+			EstimatedMonthlySavings: fixtureAmount(instance.MonthlyCost),
+		}
+		return recommendation
+	}
+
+	recommendation.RightsizingType = awstypes.RightsizingTypeModify
+	detail := &awstypes.ModifyRecommendationDetail{}
+	for i, target := range instance.Targets {
+		expectedCPU := instance.CPU * instance.MonthlyCost / target.MonthlyCost
+		detail.TargetInstances = append(detail.TargetInstances, awstypes.TargetInstance{
+			CurrencyCode:            aws.String("USD"),
+			DefaultTargetInstance:   i == 0,
+			EstimatedMonthlyCost:    fixtureAmount(target.MonthlyCost),
+			EstimatedMonthlySavings: fixtureAmount(instance.MonthlyCost - target.MonthlyCost),
+			ResourceDetails: &awstypes.ResourceDetails{EC2ResourceDetails: &awstypes.EC2ResourceDetails{
+				InstanceType: aws.String(target.InstanceType),
+				Region:       aws.String(instance.Region),
+			}},
+			ExpectedResourceUtilization: &awstypes.ResourceUtilization{EC2ResourceUtilization: &awstypes.EC2ResourceUtilization{
+				MaxCpuUtilizationPercentage: fixtureAmount(expectedCPU),
+			}},
+		})
+	}
+	recommendation.ModifyRecommendationDetail = detail
+	return recommendation
+}
+
+// fixturePlanPurchase is a canned Savings Plans purchase recommendation: the
+// hourly on-demand spend it covers and its one-year, no upfront discount
+type fixturePlanPurchase struct {
+	Region, InstanceFamily string
+	OnDemandHourly         float64
+	Discount               float64
+}
+
+// fixturePlanPurchases holds the canned recommendations per Savings Plans type
+var fixturePlanPurchases = map[awstypes.SupportedSavingsPlansType][]fixturePlanPurchase{
+	awstypes.SupportedSavingsPlansTypeComputeSp: {
+		{"", "", 0.62, 0.17},
+	},
+	awstypes.SupportedSavingsPlansTypeEc2InstanceSp: {
+		{"us-east-1", "m5", 0.38, 0.28},
+		{"us-west-2", "c5", 0.21, 0.27},
+	},
+}
+
+// fixtureReservationPurchase is a canned reservation purchase recommendation:
+// instances to buy, their hourly on-demand rate and one-year, no upfront discount
+type fixtureReservationPurchase struct {
+	InstanceType, Region string
+	Count                int
+	OnDemandRate         float64
+	Discount             float64
+}
+
+// fixtureReservationPurchases holds the canned recommendations per service
+var fixtureReservationPurchases = map[string][]fixtureReservationPurchase{
+	"Amazon Elastic Compute Cloud - Compute": {
+		{"m5.xlarge", "us-east-1", 4, 0.192, 0.31},
+		{"t3.medium", "us-west-2", 6, 0.0416, 0.29},
+	},
+	"Amazon Relational Database Service": {
+		{"db.r5.large", "eu-west-1", 1, 0.25, 0.34},
+	},
+}
+
+// fixtureDiscount adjusts a one-year, no upfront discount for the options:
+// longer terms and more upfront payment save more
+func fixtureDiscount(discount float64, term awstypes.TermInYears, payment awstypes.PaymentOption) float64 {
+	if term == awstypes.TermInYearsThreeYears {
+		discount += 0.18
+	}
+	switch payment {
+	case awstypes.PaymentOptionPartialUpfront:
+		discount += 0.02
+	case awstypes.PaymentOptionAllUpfront:
+		discount += 0.04
+	}
+	return discount
+}
+
+// fixtureLookbackScale scales the usage a recommendation covers by lookback
+func fixtureLookbackScale(lookback awstypes.LookbackPeriodInDays) float64 {
+	switch lookback {
+	case awstypes.LookbackPeriodInDaysSevenDays:
+		return 0.9
+	case awstypes.LookbackPeriodInDaysSixtyDays:
+		return 1.05
+	}
+	return 1
+}
+
+// fixtureUpfront returns the upfront cost of a commitment of hourly cost
+func fixtureUpfront(hourly float64, term awstypes.TermInYears, payment awstypes.PaymentOption) float64 {
+	hours := 8760.0
+	if term == awstypes.TermInYearsThreeYears {
+		hours *= 3
+	}
+	switch payment {
+	case awstypes.PaymentOptionPartialUpfront:
+		return hourly * hours / 2
+	case awstypes.PaymentOptionAllUpfront:
+		return hourly * hours
+	}
+	return 0
+}
+
+// GetSavingsPlansPurchaseRecommendation returns the fixture recommendations
+// of the requested Savings Plans type, priced for the requested options
+func (f *FakeClient) GetSavingsPlansPurchaseRecommendation(ctx context.Context, params *costexplorer.GetSavingsPlansPurchaseRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansPurchaseRecommendationOutput, error) {
+	f.record("GetSavingsPlansPurchaseRecommendation")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	recommendation := &awstypes.SavingsPlansPurchaseRecommendation{
+		SavingsPlansType:     params.SavingsPlansType,
+		TermInYears:          params.TermInYears,
+		PaymentOption:        params.PaymentOption,
+		LookbackPeriodInDays: params.LookbackPeriodInDays,
+	}
+	for _, purchase := range fixturePlanPurchases[params.SavingsPlansType] {
+		onDemand := purchase.OnDemandHourly * fixtureLookbackScale(params.LookbackPeriodInDays)
+		discount := fixtureDiscount(purchase.Discount, params.TermInYears, params.PaymentOption)
+		commitment := onDemand * (1 - discount)
+		upfront := fixtureUpfront(commitment, params.TermInYears, params.PaymentOption)
+		monthlySavings := (onDemand - commitment) * hoursPerMonth
+
+		recommendation.SavingsPlansPurchaseRecommendationDetails = append(recommendation.SavingsPlansPurchaseRecommendationDetails, awstypes.SavingsPlansPurchaseRecommendationDetail{
+			AccountId:                         aws.String("111111111111"),
+			CurrencyCode:                      aws.String("USD"),
+			HourlyCommitmentToPurchase:        fixtureAmount(commitment),
+			UpfrontCost:                       fixtureAmount(upfront),
+			EstimatedSPCost:                   fixtureAmount(commitment * hoursPerMonth),
+			EstimatedOnDemandCost:             fixtureAmount(onDemand * hoursPerMonth),
+			EstimatedSavingsAmount:            fixtureAmount(monthlySavings),
+			EstimatedMonthlySavingsAmount:     fixtureAmount(monthlySavings),
+			EstimatedSavingsPercentage:        fixtureAmount(discount * 100),
+			EstimatedAverageUtilization:       fixtureAmount(97.5),
+			EstimatedROI:                      fixtureAmount(percentOf(onDemand-commitment, commitment)),
+			CurrentAverageHourlyOnDemandSpend: fixtureAmount(onDemand),
+			CurrentMinimumHourlyOnDemandSpend: fixtureAmount(onDemand * 0.8),
+			CurrentMaximumHourlyOnDemandSpend: fixtureAmount(onDemand * 1.3),
+			SavingsPlansDetails: &awstypes.SavingsPlansDetails{
+				Region:         aws.String(purchase.Region),
+				InstanceFamily: aws.String(purchase.InstanceFamily),
+				OfferingId:     aws.String(fmt.Sprintf("%s-%s", params.SavingsPlansType, params.TermInYears)),
+			},
+		})
+	}
+
+	return &costexplorer.GetSavingsPlansPurchaseRecommendationOutput{SavingsPlansPurchaseRecommendation: recommendation}, nil
+}
+
+// GetReservationPurchaseRecommendation returns the fixture recommendations
+// of the requested service, priced for the requested options
+func (f *FakeClient) GetReservationPurchaseRecommendation(ctx context.Context, params *costexplorer.GetReservationPurchaseRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationPurchaseRecommendationOutput, error) {
+	f.record("GetReservationPurchaseRecommendation")
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.NoCommitments {
+		return nil, errNoCommitments
+	}
+
+	service := aws.ToString(params.Service)
+	purchases := fixtureReservationPurchases[service]
+	if len(purchases) == 0 {
+		return &costexplorer.GetReservationPurchaseRecommendationOutput{}, nil
+	}
+
+	recommendation := awstypes.ReservationPurchaseRecommendation{
+		TermInYears:          params.TermInYears,
+		PaymentOption:        params.PaymentOption,
+		LookbackPeriodInDays: params.LookbackPeriodInDays,
+	}
+	for _, purchase := range purchases {
+		count := float64(purchase.Count)
+		used := count * fixtureLookbackScale(params.LookbackPeriodInDays)
+		onDemand := count * purchase.OnDemandRate * hoursPerMonth
+		discount := fixtureDiscount(purchase.Discount, params.TermInYears, params.PaymentOption)
+		reserved := onDemand * (1 - discount)
+		upfront := fixtureUpfront(reserved/hoursPerMonth, params.TermInYears, params.PaymentOption)
+		months := 12.0
+		if params.TermInYears == awstypes.TermInYearsThreeYears {
+			months = 36
+		}
+		recurring := reserved - upfront/months
+
+		details := &awstypes.InstanceDetails{}
+		if strings.HasPrefix(purchase.InstanceType, "db.") {
+			details.RDSInstanceDetails = &awstypes.RDSInstanceDetails{
+				InstanceType:     aws.String(purchase.InstanceType),
+				Region:           aws.String(purchase.Region),
+				DatabaseEngine:   aws.String("PostgreSQL"),
+				DeploymentOption: aws.String("Multi-AZ"),
+			}
+		} else {
+			details.EC2InstanceDetails = &awstypes.EC2InstanceDetails{
+				InstanceType: aws.String(purchase.InstanceType),
+				Region:       aws.String(purchase.Region),
+				Platform:     aws.String("Linux/UNIX"),
+			}
+		}
+
+		recommendation.RecommendationDetails = append(recommendation.RecommendationDetails, awstypes.ReservationPurchaseRecommendationDetail{
+			AccountId:                              aws.String("111111111111"),
+			CurrencyCode:                           aws.String("USD"),
+			InstanceDetails:                        details,
+			RecommendedNumberOfInstancesToPurchase: fixtureAmount(count),
+			UpfrontCost:                            fixtureAmount(upfront),
+			RecurringStandardMonthlyCost:           fixtureAmount(recurring),
+			EstimatedMonthlyOnDemandCost:           fixtureAmount(onDemand),
+			EstimatedMonthlySavingsAmount:          fixtureAmount(onDemand - reserved),
+			EstimatedMonthlySavingsPercentage:      fixtureAmount(discount * 100),
+			EstimatedBreakEvenInMonths:             fixtureAmount(upfront / (onDemand - recurring)),
+			AverageUtilization:                     fixtureAmount(percentOf(used, count*1.05)),
+			AverageNumberOfInstancesUsedPerHour:    fixtureAmount(used),
+			MinimumNumberOfInstancesUsedPerHour:    fixtureAmount(used * 0.7),
+			MaximumNumberOfInstancesUsedPerHour:    fixtureAmount(used * 1.2),
+		})
+	}
+
+	return &costexplorer.GetReservationPurchaseRecommendationOutput{
+		Recommendations: []awstypes.ReservationPurchaseRecommendation{recommendation},
+	}, nil
+}
@@ -0,0 +1,168 @@
+package aws
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// RecordTypes are the common values of the RECORD_TYPE dimension, offered
+// when choosing which to leave out of the service, region and account views
+var RecordTypes = []string{
+	"Usage",
+	"DiscountedUsage",
+	"SavingsPlanCoveredUsage",
+	"SavingsPlanNegation",
+	"SavingsPlanRecurringFee",
+	"SavingsPlanUpfrontFee",
+	"RIFee",
+	"Fee",
+	"Support",
+	"Credit",
+	"Refund",
+	"Tax",
+	"BundledDiscount",
+	"EdpDiscount",
+}
+
+// FilterDimensions are the dimensions offered by the filter builder
+var FilterDimensions = []string{"SERVICE", "REGION", "LINKED_ACCOUNT", "RECORD_TYPE"}
+
+// ListDimensionValues lists the values of a dimension with costs in the query's
+// range, or its months of history, sorted for autocompletion
+func ListDimensionValues(client types.CostExplorerAPI, q types.Query, dimension string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	period := toInterval(historyRange(q, time.Now()))
+	input := &costexplorer.GetDimensionValuesInput{
+		TimePeriod: &period,
+		Dimension:  awstypes.Dimension(dimension),
+	}
+
+	var values []string
+	for {
+		page, err := client.GetDimensionValues(ctx, input)
+		if err != nil {
+			return nil, classifyError(ctx, "GetDimensionValues", err)
+		}
+		for _, value := range page.DimensionValues {
+			values = append(values, aws.ToString(value.Value))
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	sort.Strings(values)
+	return values, nil
+}
+
+// filterIgnoredNotice tells that the filter was not sent with requests for
+// what, which accept only a few dimensions or none
+func filterIgnoredNotice(what string) string {
+	return "The filter does not apply to " + what
+}
+
+// queryFilter builds the Cost Explorer filter for a query's filter and any
+// drill-down path, or returns nil when there is nothing to filter on
+func queryFilter(q types.Query, path ...types.DimensionFilter) *awstypes.Expression {
+	var expressions []awstypes.Expression
+	if expression := filterExpression(q.Filter); expression != nil {
+		expressions = append(expressions, *expression)
+	}
+	for _, filter := range path {
+		expressions = append(expressions, awstypes.Expression{
+			Dimensions: &awstypes.DimensionValues{
+				Key:    awstypes.Dimension(filter.Dimension),
+				Values: filter.Values,
+			},
+		})
+	}
+	return combineExpressions(expressions, false)
+}
+
+// totalsFilter is queryFilter for the per-service, per-region and per-account
+// views and their drill-downs, which also leave out the query's excluded
+// record types
+func totalsFilter(q types.Query, path ...types.DimensionFilter) *awstypes.Expression {
+	expression := queryFilter(q, path...)
+	if len(q.ExcludedRecordTypes) == 0 {
+		return expression
+	}
+
+	exclude := awstypes.Expression{Not: &awstypes.Expression{
+		Dimensions: &awstypes.DimensionValues{
+			Key:    awstypes.DimensionRecordType,
+			Values: q.ExcludedRecordTypes,
+		},
+	}}
+	if expression == nil {
+		return &exclude
+	}
+	return combineExpressions([]awstypes.Expression{*expression, exclude}, false)
+}
+
+// filterExpression converts a filter to an expression tree: each condition
+// becomes a Dimensions or Tags match, wrapped in Not when excluded, the
+// conditions of a group are joined with its And or Or, and the groups with
+// the filter's
+func filterExpression(f types.Filter) *awstypes.Expression {
+	var groups []awstypes.Expression
+	for _, group := range f.Groups {
+		var expressions []awstypes.Expression
+		for _, condition := range group.Conditions {
+			expressions = append(expressions, conditionExpression(condition))
+		}
+		if expression := combineExpressions(expressions, group.Any); expression != nil {
+			groups = append(groups, *expression)
+		}
+	}
+	return combineExpressions(groups, f.Any)
+}
+
+// conditionExpression converts a single filter condition
+func conditionExpression(condition types.FilterCondition) awstypes.Expression {
+	var expression awstypes.Expression
+	if condition.Dimension != "" {
+		expression.Dimensions = &awstypes.DimensionValues{
+			Key:    awstypes.Dimension(condition.Dimension),
+			Values: condition.Values,
+		}
+	} else {
+		expression.Tags = &awstypes.TagValues{
+			Key:    aws.String(condition.TagKey),
+			Values: condition.Values,
+		}
+	}
+
+	if condition.Exclude {
+		return awstypes.Expression{Not: &expression}
+	}
+	return expression
+}
+
+// combineExpressions joins expressions with And, or Or when matchAny is set.
+// Cost Explorer requires at least two operands, so a single expression is
+// returned as is.
+func combineExpressions(expressions []awstypes.Expression, matchAny bool) *awstypes.Expression {
+	switch len(expressions) {
+	case 0:
+		return nil
+	case 1:
+		return &expressions[0]
+	}
+
+	if matchAny {
+		return &awstypes.Expression{Or: expressions}
+	}
+	return &awstypes.Expression{And: expressions}
+}
@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"testing"
+
+	"cost-explorer/internal/types"
+)
+
+func TestFilterExpressionGroups(t *testing.T) {
+	service := types.FilterCondition{Dimension: "SERVICE", Values: []string{"A"}}
+	region := types.FilterCondition{Dimension: "REGION", Values: []string{"B"}}
+	env := types.FilterCondition{TagKey: "env", Values: []string{"prod"}, Exclude: true}
+
+	filter := types.Filter{
+		Groups: []types.FilterGroup{
+			{Conditions: []types.FilterCondition{service, region}},
+			{Conditions: []types.FilterCondition{env}},
+			{},
+		},
+		Any: true,
+	}
+
+	if got, want := filter.String(), "(SERVICE = A AND REGION = B) OR NOT tag:env = prod"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	expression := filterExpression(filter)
+	if len(expression.Or) != 2 {
+		t.Fatalf("got %d Or operands, want 2", len(expression.Or))
+	}
+	if and := expression.Or[0].And; len(and) != 2 || string(and[0].Dimensions.Key) != "SERVICE" || string(and[1].Dimensions.Key) != "REGION" {
+		t.Errorf("first operand is not SERVICE AND REGION: %+v", expression.Or[0])
+	}
+	if not := expression.Or[1].Not; not == nil || not.Tags == nil || *not.Tags.Key != "env" {
+		t.Errorf("second operand is not NOT tag:env: %+v", expression.Or[1])
+	}
+
+	// A single group is sent without a wrapping operator
+	single := filterExpression(types.Filter{Groups: []types.FilterGroup{{Conditions: []types.FilterCondition{service, region}, Any: true}}})
+	if len(single.Or) != 2 || single.And != nil {
+		t.Errorf("single group became %+v, want SERVICE OR REGION", single)
+	}
+}
@@ -0,0 +1,166 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// DefaultForecastMonths is the number of months the Forecast view covers by default
+const DefaultForecastMonths = 3
+
+// MaxForecastMonths is the longest monthly forecast offered; Cost Explorer
+// forecasts up to 12 months ahead
+const MaxForecastMonths = 12
+
+// ForecastDays is the length of the daily forecast
+const ForecastDays = 30
+
+// forecastIntervalLevel is the confidence, in percent, of the prediction bounds
+const forecastIntervalLevel = 80
+
+// GetForecastData fetches the cost forecast with its prediction interval. A
+// monthly forecast covers the given number of months from next month and
+// compares each month with the same month last year; a daily forecast
+// covers the next ForecastDays days.
+func GetForecastData(client types.CostExplorerAPI, q types.Query, months int, daily bool) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	now := time.Now()
+	granularity := awstypes.GranularityMonthly
+	forecastRange := types.DateRange{Start: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)}
+	forecastRange.End = forecastRange.Start.AddDate(0, months, 0)
+	if daily {
+		granularity = awstypes.GranularityDaily
+		forecastRange = types.DateRange{Start: today(now), End: today(now).AddDate(0, 0, ForecastDays)}
+	}
+	period := toInterval(forecastRange)
+
+	forecast, err := client.GetCostForecast(ctx, &costexplorer.GetCostForecastInput{
+		TimePeriod:              &period,
+		Granularity:             granularity,
+		Metric:                  forecastMetric(metric),
+		Filter:                  queryFilter(q),
+		PredictionIntervalLevel: aws.Int32(forecastIntervalLevel),
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostForecast", err)
+	}
+
+	unit := metricValueUnit(forecast.Total)
+	columns := []types.Column{
+		{Title: "Month"},
+		{Title: "Forecast", Kind: types.KindMoney, Unit: unit},
+		{Title: fmt.Sprintf("Lower (%d%%)", forecastIntervalLevel), Kind: types.KindMoney, Unit: unit},
+		{Title: fmt.Sprintf("Upper (%d%%)", forecastIntervalLevel), Kind: types.KindMoney, Unit: unit},
+	}
+
+	var rows [][]types.Cell
+	if daily {
+		columns[0].Title = "Date"
+		for _, result := range forecast.ForecastResultsByTime {
+			rows = append(rows, []types.Cell{
+				types.TextCell(aws.ToString(result.TimePeriod.Start)),
+				types.ValueCell(parseAmount(result.MeanValue)),
+				types.ValueCell(parseAmount(result.PredictionIntervalLowerBound)),
+				types.ValueCell(parseAmount(result.PredictionIntervalUpperBound)),
+			})
+		}
+
+		return types.CostData{
+			Title:   fmt.Sprintf("🔮 Forecast (next %d days)", ForecastDays),
+			Columns: columns,
+			Rows:    rows,
+		}, nil
+	}
+
+	// Actual costs of the same months a year earlier
+	lastYearPeriod := toInterval(types.DateRange{
+		Start: forecastRange.Start.AddDate(-1, 0, 0),
+		End:   forecastRange.End.AddDate(-1, 0, 0),
+	})
+	lastYear, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &lastYearPeriod,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	lastYearCosts := make(map[string]float64)
+	for _, result := range lastYear.ResultsByTime {
+		if amount, _, exists := metricAmount(result.Total, metric); exists {
+			lastYearCosts[aws.ToString(result.TimePeriod.Start)] = amount
+		}
+	}
+
+	columns = append(columns,
+		types.Column{Title: "Last Year", Kind: types.KindMoney, Unit: unit},
+		types.Column{Title: "vs Last Year", Kind: types.KindPercent},
+	)
+
+	// Only months with history count towards the total compared with last year
+	var total, comparedTotal, totalLastYear float64
+	for _, result := range forecast.ForecastResultsByTime {
+		start, err := time.Parse("2006-01-02", aws.ToString(result.TimePeriod.Start))
+		if err != nil {
+			continue
+		}
+
+		mean := parseAmount(result.MeanValue)
+		previous := lastYearCosts[start.AddDate(-1, 0, 0).Format("2006-01-02")]
+		total += mean
+		if previous != 0 {
+			comparedTotal += mean
+			totalLastYear += previous
+		}
+
+		rows = append(rows, append([]types.Cell{
+			types.TextCell(start.Format("January 2006")),
+			types.ValueCell(mean),
+			types.ValueCell(parseAmount(result.PredictionIntervalLowerBound)),
+			types.ValueCell(parseAmount(result.PredictionIntervalUpperBound)),
+		}, lastYearCells(previous, mean)...))
+	}
+
+	// The bounds of individual months do not add up, so the total has none
+	if len(rows) > 1 {
+		rows = append(rows, append([]types.Cell{
+			types.TextCell("Total"),
+			types.ValueCell(total),
+			types.BlankCell(),
+			types.BlankCell(),
+		}, lastYearCells(totalLastYear, comparedTotal)...))
+	}
+
+	return types.CostData{
+		Title:   fmt.Sprintf("🔮 Forecast (next %d months)", months),
+		Columns: columns,
+		Rows:    rows,
+	}, nil
+}
+
+// lastYearCells returns last year's cost and the change from it to current in
+// percent, both blank when there was no cost last year to compare with
+func lastYearCells(previous, current float64) []types.Cell {
+	if previous == 0 {
+		return []types.Cell{types.BlankCell(), types.BlankCell()}
+	}
+	return []types.Cell{
+		types.ValueCell(previous),
+		types.ValueCell((current - previous) / previous * 100),
+	}
+}
@@ -0,0 +1,81 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+)
+
+// missingHistoryClient drops the first month of every GetCostAndUsage
+// response, like an account created partway through last year
+type missingHistoryClient struct {
+	*FakeClient
+}
+
+func (c missingHistoryClient) GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error) {
+	output, err := c.FakeClient.GetCostAndUsage(ctx, params, optFns...)
+	if err == nil && len(output.ResultsByTime) > 0 {
+		output.ResultsByTime = output.ResultsByTime[1:]
+	}
+	return output, err
+}
+
+func TestLastYearCells(t *testing.T) {
+	tests := []struct {
+		name              string
+		previous, current float64
+		wantBlank         bool
+		wantChange        float64
+	}{
+		{"no history", 0, 120, true, 0},
+		{"growth", 100, 120, false, 20},
+		{"decline", 200, 150, false, -25},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells := lastYearCells(tt.previous, tt.current)
+			if len(cells) != 2 {
+				t.Fatalf("got %d cells, want 2", len(cells))
+			}
+			if cells[0].Blank != tt.wantBlank || cells[1].Blank != tt.wantBlank {
+				t.Fatalf("blank = %t, %t, want %t", cells[0].Blank, cells[1].Blank, tt.wantBlank)
+			}
+			if !tt.wantBlank && (cells[0].Value != tt.previous || cells[1].Value != tt.wantChange) {
+				t.Errorf("got %.2f and %.2f%%, want %.2f and %.2f%%", cells[0].Value, cells[1].Value, tt.previous, tt.wantChange)
+			}
+		})
+	}
+}
+
+func TestForecastTotalSkipsMonthsWithoutHistory(t *testing.T) {
+	data, err := GetForecastData(missingHistoryClient{NewFakeClient()}, types.Query{}, 3, false)
+	if err != nil {
+		t.Fatalf("GetForecastData: %v", err)
+	}
+	if len(data.Rows) != 4 {
+		t.Fatalf("got %d rows, want 3 months and a total", len(data.Rows))
+	}
+
+	months, total := data.Rows[:3], data.Rows[3]
+	if !months[0][4].Blank || !months[0][5].Blank {
+		t.Errorf("first month compared with last year although it has no history")
+	}
+
+	var forecast, lastYear float64
+	for _, row := range months[1:] {
+		forecast += row[1].Value
+		lastYear += row[4].Value
+	}
+	if math.Abs(total[4].Value-lastYear) > 0.001 {
+		t.Errorf("total last year = %.2f, want %.2f", total[4].Value, lastYear)
+	}
+	want := (forecast - lastYear) / lastYear * 100
+	if math.Abs(total[5].Value-want) > 0.001 {
+		t.Errorf("total vs last year = %.2f%%, want %.2f%% over the months with history", total[5].Value, want)
+	}
+}
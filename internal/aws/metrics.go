@@ -0,0 +1,82 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// DefaultMetric is the cost metric used when none is selected
+const DefaultMetric = "NetUnblendedCost"
+
+// Metrics lists the cost metrics that can be selected, in switching order
+var Metrics = []string{
+	"UnblendedCost",
+	"AmortizedCost",
+	"BlendedCost",
+	"NetAmortizedCost",
+	"NetUnblendedCost",
+}
+
+// metricLabels are the display names shown in the header
+var metricLabels = map[string]string{
+	"UnblendedCost":    "Unblended",
+	"AmortizedCost":    "Amortized",
+	"BlendedCost":      "Blended",
+	"NetAmortizedCost": "Net Amortized",
+	"NetUnblendedCost": "Net Unblended",
+}
+
+// forecastMetrics maps GetCostAndUsage metric names to GetCostForecast enums
+var forecastMetrics = map[string]awstypes.Metric{
+	"UnblendedCost":    awstypes.MetricUnblendedCost,
+	"AmortizedCost":    awstypes.MetricAmortizedCost,
+	"BlendedCost":      awstypes.MetricBlendedCost,
+	"NetAmortizedCost": awstypes.MetricNetAmortizedCost,
+	"NetUnblendedCost": awstypes.MetricNetUnblendedCost,
+}
+
+// ParseMetric resolves a metric name case-insensitively
+func ParseMetric(name string) (string, error) {
+	for _, metric := range Metrics {
+		if strings.EqualFold(metric, name) {
+			return metric, nil
+		}
+	}
+	return "", fmt.Errorf("unknown metric %q, expected one of %s", name, strings.Join(Metrics, ", "))
+}
+
+// NextMetric returns the metric after the given one, wrapping around
+func NextMetric(metric string) string {
+	for i, candidate := range Metrics {
+		if candidate == metric {
+			return Metrics[(i+1)%len(Metrics)]
+		}
+	}
+	return DefaultMetric
+}
+
+// MetricLabel returns the display name of a metric
+func MetricLabel(metric string) string {
+	if label, exists := metricLabels[metric]; exists {
+		return label
+	}
+	return metric
+}
+
+// queryMetric returns the query's metric, falling back to the default
+func queryMetric(metric string) string {
+	if metric == "" {
+		return DefaultMetric
+	}
+	return metric
+}
+
+// forecastMetric returns the forecast enum for a metric
+func forecastMetric(metric string) awstypes.Metric {
+	if forecast, exists := forecastMetrics[queryMetric(metric)]; exists {
+		return forecast
+	}
+	return awstypes.MetricNetUnblendedCost
+}
@@ -0,0 +1,68 @@
+package aws
+
+import (
+	"context"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// getAllCostAndUsage calls GetCostAndUsage until NextPageToken is exhausted and
+// merges every page into a single output. Cost Explorer repeats the same time
+// periods on each page with a different slice of groups, so groups are merged
+// into the result for their period rather than appended as new periods.
+func getAllCostAndUsage(ctx context.Context, client types.CostExplorerAPI, input *costexplorer.GetCostAndUsageInput) (*costexplorer.GetCostAndUsageOutput, error) {
+	// Copy the input so the caller's struct is not mutated by the token
+	params := *input
+	params.NextPageToken = nil
+
+	merged := &costexplorer.GetCostAndUsageOutput{}
+	periodIndex := make(map[string]int)
+
+	for {
+		page, err := client.GetCostAndUsage(ctx, &params)
+		if err != nil {
+			return nil, err
+		}
+
+		if merged.GroupDefinitions == nil {
+			merged.GroupDefinitions = page.GroupDefinitions
+		}
+		merged.DimensionValueAttributes = append(merged.DimensionValueAttributes, page.DimensionValueAttributes...)
+
+		for _, result := range page.ResultsByTime {
+			key := periodKey(result.TimePeriod)
+			index, exists := periodIndex[key]
+			if !exists {
+				periodIndex[key] = len(merged.ResultsByTime)
+				merged.ResultsByTime = append(merged.ResultsByTime, result)
+				continue
+			}
+
+			existing := &merged.ResultsByTime[index]
+			existing.Groups = append(existing.Groups, result.Groups...)
+			if len(existing.Total) == 0 {
+				existing.Total = result.Total
+			}
+			existing.Estimated = existing.Estimated || result.Estimated
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		params.NextPageToken = page.NextPageToken
+	}
+
+	return merged, nil
+}
+
+// periodKey identifies a result period for merging pages
+func periodKey(period *awstypes.DateInterval) string {
+	if period == nil {
+		return ""
+	}
+	return aws.ToString(period.Start) + "/" + aws.ToString(period.End)
+}
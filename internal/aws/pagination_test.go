@@ -0,0 +1,80 @@
+package aws
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestGetAllCostAndUsage(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	period := toInterval(types.DateRange{Start: start, End: start.AddDate(0, 3, 0)})
+	groups := len(fixtureGroups["SERVICE"])
+
+	tests := []struct {
+		name      string
+		pageSize  int
+		wantPages int
+	}{
+		{"single page", 0, 1},
+		{"page per two groups", 2, (3*groups + 1) / 2},
+		{"page per period", groups, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &FakeClient{PageSize: tt.pageSize}
+			input := &costexplorer.GetCostAndUsageInput{
+				TimePeriod:  &period,
+				Granularity: awstypes.GranularityMonthly,
+				Metrics:     []string{DefaultMetric},
+				GroupBy: []awstypes.GroupDefinition{{
+					Type: awstypes.GroupDefinitionTypeDimension,
+					Key:  aws.String("SERVICE"),
+				}},
+			}
+
+			output, err := getAllCostAndUsage(context.Background(), client, input)
+			if err != nil {
+				t.Fatalf("getAllCostAndUsage: %v", err)
+			}
+
+			if got := client.Calls("GetCostAndUsage"); got != tt.wantPages {
+				t.Errorf("made %d calls, want one per page (%d)", got, tt.wantPages)
+			}
+			if input.NextPageToken != nil {
+				t.Errorf("caller's input was given token %q", *input.NextPageToken)
+			}
+
+			// Groups of a period spread over several pages land in one result
+			if len(output.ResultsByTime) != 3 {
+				t.Fatalf("got %d periods, want 3", len(output.ResultsByTime))
+			}
+			for _, result := range output.ResultsByTime {
+				if len(result.Groups) != groups {
+					t.Errorf("period %s has %d groups, want %d", aws.ToString(result.TimePeriod.Start), len(result.Groups), groups)
+				}
+			}
+			if output.NextPageToken != nil {
+				t.Errorf("merged output kept token %q", *output.NextPageToken)
+			}
+
+			// Paging does not change the amounts
+			want := fakeResults(t, NewFakeClient(), awstypes.GranularityMonthly, types.DateRange{Start: start, End: start.AddDate(0, 3, 0)}, "SERVICE")
+			got, _ := groupTotals(output.ResultsByTime, DefaultMetric, func(key string) string { return key })
+			expected, _ := groupTotals(want, DefaultMetric, func(key string) string { return key })
+			for i := range expected {
+				if got[i].Name != expected[i].Name || math.Abs(got[i].Amount-expected[i].Amount) > 0.001 {
+					t.Errorf("group %d is %s %.2f, want %s %.2f", i, got[i].Name, got[i].Amount, expected[i].Name, expected[i].Amount)
+				}
+			}
+		})
+	}
+}
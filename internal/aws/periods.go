@@ -0,0 +1,152 @@
+package aws
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// RangePreset is a named, relative date range
+type RangePreset struct {
+	ID    string // Short name accepted by --range
+	Label string // Name shown in the picker and header
+}
+
+// RangePresets lists the available presets in picker order
+var RangePresets = []RangePreset{
+	{"7d", "Last 7 days"},
+	{"30d", "Last 30 days"},
+	{"90d", "Last 90 days"},
+	{"mtd", "Month to date"},
+	{"qtd", "Quarter to date"},
+	{"ytd", "Year to date"},
+	{"last-month", "Last full month"},
+	{"12m", "Trailing 12 months"},
+}
+
+// today returns the current date at midnight UTC, as Cost Explorer uses UTC days
+func today(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// PresetRange resolves a preset, by ID or label, relative to now. Ranges
+// that run "to date" include today, so their end is tomorrow.
+func PresetRange(name string, now time.Time) (types.DateRange, error) {
+	day := today(now)
+	tomorrow := day.AddDate(0, 0, 1)
+	monthStart := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for _, preset := range RangePresets {
+		if !strings.EqualFold(preset.ID, name) && !strings.EqualFold(preset.Label, name) {
+			continue
+		}
+
+		r := types.DateRange{End: tomorrow, Label: preset.Label}
+		switch preset.ID {
+		case "7d":
+			r.Start = tomorrow.AddDate(0, 0, -7)
+		case "30d":
+			r.Start = tomorrow.AddDate(0, 0, -30)
+		case "90d":
+			r.Start = tomorrow.AddDate(0, 0, -90)
+		case "mtd":
+			r.Start = monthStart
+		case "qtd":
+			quarterMonth := time.Month((int(day.Month())-1)/3*3 + 1)
+			r.Start = time.Date(day.Year(), quarterMonth, 1, 0, 0, 0, 0, time.UTC)
+		case "ytd":
+			r.Start = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		case "last-month":
+			r.Start = monthStart.AddDate(0, -1, 0)
+			r.End = monthStart
+		case "12m":
+			r.Start = monthStart.AddDate(0, -11, 0)
+		}
+		return r, nil
+	}
+
+	return types.DateRange{}, fmt.Errorf("unknown range preset %q", name)
+}
+
+// ParseRange builds a custom range from inclusive YYYY-MM-DD dates
+func ParseRange(from, to string) (types.DateRange, error) {
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(from))
+	if err != nil {
+		return types.DateRange{}, fmt.Errorf("invalid start date %q, expected YYYY-MM-DD", from)
+	}
+	end, err := time.Parse("2006-01-02", strings.TrimSpace(to))
+	if err != nil {
+		return types.DateRange{}, fmt.Errorf("invalid end date %q, expected YYYY-MM-DD", to)
+	}
+	if end.Before(start) {
+		return types.DateRange{}, fmt.Errorf("end date %s is before start date %s", to, from)
+	}
+
+	return types.DateRange{Start: start, End: end.AddDate(0, 0, 1)}, nil
+}
+
+// toInterval converts a range to a Cost Explorer date interval
+func toInterval(r types.DateRange) awstypes.DateInterval {
+	return awstypes.DateInterval{
+		Start: aws.String(r.Start.Format("2006-01-02")),
+		End:   aws.String(r.End.Format("2006-01-02")),
+	}
+}
+
+// currentMonthRange returns the current calendar month
+func currentMonthRange(now time.Time) types.DateRange {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return types.DateRange{Start: start, End: start.AddDate(0, 1, 0), Label: "Current Month"}
+}
+
+// recentMonthsRange returns the current month and the n-1 months before it
+func recentMonthsRange(now time.Time, n int) types.DateRange {
+	end := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return types.DateRange{Start: end.AddDate(0, -n, 0), End: end, Label: fmt.Sprintf("Last %d months", n)}
+}
+
+// DefaultHistoryMonths is the number of months shown by monthly views by default
+const DefaultHistoryMonths = 3
+
+// MaxHistoryMonths is the current month plus the 13 months of history Cost Explorer keeps
+const MaxHistoryMonths = 14
+
+// historyRange returns the query's range, or its months of history up to now
+func historyRange(q types.Query, now time.Time) types.DateRange {
+	months := q.Months
+	if months <= 0 {
+		months = DefaultHistoryMonths
+	}
+	return rangeOrDefault(q, recentMonthsRange(now, months))
+}
+
+// rangeOrDefault returns the query's range, or fallback when none is set
+func rangeOrDefault(q types.Query, fallback types.DateRange) types.DateRange {
+	if q.Range.IsZero() {
+		return fallback
+	}
+	return q.Range
+}
+
+// monthStarts returns the first day of every month overlapping the range, oldest first
+func monthStarts(r types.DateRange) []time.Time {
+	var months []time.Time
+	for month := time.Date(r.Start.Year(), r.Start.Month(), 1, 0, 0, 0, 0, time.UTC); month.Before(r.End); month = month.AddDate(0, 1, 0) {
+		months = append(months, month)
+	}
+	return months
+}
+
+// throughToday ends a range no later than today, as utilization and coverage
+// cannot be requested for future dates
+func throughToday(r types.DateRange, now time.Time) types.DateRange {
+	if tomorrow := today(now).AddDate(0, 0, 1); r.End.After(tomorrow) {
+		r.End = tomorrow
+	}
+	return r
+}
@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// DefaultProfile is the profile used when none is selected
+const DefaultProfile = "default"
+
+// ListProfiles returns the names of the profiles in the shared config and
+// credentials files, sorted, honoring AWS_CONFIG_FILE and
+// AWS_SHARED_CREDENTIALS_FILE
+func ListProfiles() ([]string, error) {
+	configFile := os.Getenv("AWS_CONFIG_FILE")
+	if configFile == "" {
+		configFile = config.DefaultSharedConfigFilename()
+	}
+	credentialsFile := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if credentialsFile == "" {
+		credentialsFile = config.DefaultSharedCredentialsFilename()
+	}
+
+	seen := make(map[string]bool)
+	// Config sections are named "profile x" apart from the default one, while
+	// credentials sections are the bare profile name
+	for _, file := range []struct {
+		path   string
+		prefix string
+	}{
+		{configFile, "profile "},
+		{credentialsFile, ""},
+	} {
+		sections, err := iniSections(file.path)
+		if err != nil {
+			return nil, err
+		}
+		for _, section := range sections {
+			name, isProfile := strings.CutPrefix(section, file.prefix)
+			if section == DefaultProfile {
+				name, isProfile = section, true
+			}
+			// Skip sso-session and services sections of the config file
+			if isProfile && name != "" && !strings.Contains(name, " ") {
+				seen[name] = true
+			}
+		}
+	}
+
+	profiles := make([]string, 0, len(seen))
+	for name := range seen {
+		profiles = append(profiles, name)
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// CurrentProfile names the profile a client without an explicit profile uses
+func CurrentProfile() string {
+	if profile := os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return DefaultProfile
+}
+
+// iniSections returns the section names of an INI file, or none when the file
+// does not exist
+func iniSections(path string) ([]string, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var sections []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Section lines may end in a comment, e.g. "[profile x] # staging"
+		line := strings.TrimSpace(scanner.Text())
+		name, rest, closed := strings.Cut(strings.TrimPrefix(line, "["), "]")
+		rest = strings.TrimSpace(rest)
+		if !strings.HasPrefix(line, "[") || !closed || (rest != "" && rest[0] != '#' && rest[0] != ';') {
+			continue
+		}
+		sections = append(sections, strings.Join(strings.Fields(name), " "))
+	}
+	return sections, scanner.Err()
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeFile writes contents to name in dir and returns its path
+func writeFile(t *testing.T, dir, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", writeFile(t, dir, "config", `
+# [profile commented]
+; [profile also-commented]
+[default]
+region = us-east-1
+
+[profile dev]
+region = eu-west-1
+[profile   staging]   # spaced and commented
+[shared]
+[sso-session corp]
+sso_region = us-east-1
+[services local]
+[profile prod]
+`))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", writeFile(t, dir, "credentials", `
+[default]
+aws_access_key_id = x
+[dev]
+[ci] ; used by the pipeline
+[profile odd]
+`))
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	// "shared" is a credentials-style section in the config file, and
+	// "profile odd" a config-style one in the credentials file; the CLI reads
+	// neither as a profile
+	want := []string{"ci", "default", "dev", "prod", "staging"}
+	if !slices.Equal(profiles, want) {
+		t.Errorf("got %v, want %v", profiles, want)
+	}
+}
+
+func TestListProfilesWithoutFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "missing-config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "missing-credentials"))
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles: %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("got %v, want no profiles", profiles)
+	}
+}
+
+func TestIniSections(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config", `
+[default]
+key = [not a section]
+  [ profile  indented ]
+[profile x] # comment
+[profile y] trailing text
+[unclosed
+# [commented]
+`)
+
+	sections, err := iniSections(path)
+	if err != nil {
+		t.Fatalf("iniSections: %v", err)
+	}
+	want := []string{"default", "profile indented", "profile x"}
+	if !slices.Equal(sections, want) {
+		t.Errorf("got %q, want %q", sections, want)
+	}
+}
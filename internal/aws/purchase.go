@@ -0,0 +1,370 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// The purchase recommendation view recommends either of these commitments
+const (
+	PurchaseSavingsPlans = "Savings Plans"
+	PurchaseReservations = "Reserved Instances"
+)
+
+// PurchaseKinds lists the commitments in toggle order
+var PurchaseKinds = []string{PurchaseSavingsPlans, PurchaseReservations}
+
+// PurchaseTerms, PurchasePayments and PurchaseLookbacks list the options of
+// a purchase recommendation in toggle order
+var (
+	PurchaseTerms     = []string{"1 year", "3 years"}
+	PurchasePayments  = []string{"No Upfront", "Partial Upfront", "All Upfront"}
+	PurchaseLookbacks = []string{"7 days", "30 days", "60 days"}
+)
+
+var purchaseTermValues = map[string]awstypes.TermInYears{
+	"1 year":  awstypes.TermInYearsOneYear,
+	"3 years": awstypes.TermInYearsThreeYears,
+}
+
+var purchasePaymentValues = map[string]awstypes.PaymentOption{
+	"No Upfront":      awstypes.PaymentOptionNoUpfront,
+	"Partial Upfront": awstypes.PaymentOptionPartialUpfront,
+	"All Upfront":     awstypes.PaymentOptionAllUpfront,
+}
+
+var purchaseLookbackValues = map[string]awstypes.LookbackPeriodInDays{
+	"7 days":  awstypes.LookbackPeriodInDaysSevenDays,
+	"30 days": awstypes.LookbackPeriodInDaysThirtyDays,
+	"60 days": awstypes.LookbackPeriodInDaysSixtyDays,
+}
+
+// savingsPlansTypes are the Savings Plans recommended, with their labels.
+// Each type is requested separately.
+var savingsPlansTypes = []struct {
+	Type  awstypes.SupportedSavingsPlansType
+	Label string
+}{
+	{awstypes.SupportedSavingsPlansTypeComputeSp, "Compute"},
+	{awstypes.SupportedSavingsPlansTypeEc2InstanceSp, "EC2 Instance"},
+	{awstypes.SupportedSavingsPlansTypeSagemakerSp, "SageMaker"},
+}
+
+// hoursPerMonth converts monthly reservation costs to an hourly commitment
+const hoursPerMonth = 730
+
+// Columns of the purchase recommendation tables the view sorts by
+const (
+	PurchaseSavingsColumn = "Est. Monthly Savings"
+	PurchaseUpfrontColumn = "Upfront Cost"
+)
+
+// PurchaseOptions are the settings of a purchase recommendation
+type PurchaseOptions struct {
+	Kind     string // PurchaseSavingsPlans or PurchaseReservations
+	Term     string // One of PurchaseTerms
+	Payment  string // One of PurchasePayments
+	Lookback string // One of PurchaseLookbacks
+}
+
+// String describes the options, e.g. "1 year, No Upfront, 30 days lookback"
+func (o PurchaseOptions) String() string {
+	return fmt.Sprintf("%s, %s, %s lookback", o.Term, o.Payment, o.Lookback)
+}
+
+// GetPurchaseRecommendationData fetches the Savings Plans or Reserved
+// Instance purchases Cost Explorer recommends for the given term, payment
+// option and lookback period, one row per recommendation
+func GetPurchaseRecommendationData(client types.CostExplorerAPI, q types.Query, options PurchaseOptions) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var data types.CostData
+	var err error
+	if options.Kind == PurchaseReservations {
+		data, err = reservationPurchases(ctx, client, options)
+	} else {
+		data, err = savingsPlansPurchases(ctx, client, options)
+	}
+
+	// Cost Explorer has no recommendations until it has enough usage history
+	if isDataUnavailable(err) {
+		return types.CostData{
+			Title:  fmt.Sprintf("🛒 %s Purchase Recommendations", options.Kind),
+			Notice: fmt.Sprintf("No %s purchase recommendations are available yet", options.Kind),
+		}, nil
+	}
+	if err != nil {
+		return types.CostData{}, err
+	}
+
+	if len(data.Rows) == 0 {
+		data.Notice = fmt.Sprintf("No %s purchase recommendations for %s", options.Kind, options)
+	} else if !q.Filter.IsZero() {
+		data.Notice = filterIgnoredNotice("purchase recommendations")
+	}
+	return data, nil
+}
+
+// savingsPlansPurchases builds a row per recommended Savings Plan of each type
+func savingsPlansPurchases(ctx context.Context, client types.CostExplorerAPI, options PurchaseOptions) (types.CostData, error) {
+	unit := "USD"
+	var rows [][]types.Cell
+	var details [][]types.DetailField
+
+	for _, planType := range savingsPlansTypes {
+		input := &costexplorer.GetSavingsPlansPurchaseRecommendationInput{
+			SavingsPlansType:     planType.Type,
+			TermInYears:          purchaseTermValues[options.Term],
+			PaymentOption:        purchasePaymentValues[options.Payment],
+			LookbackPeriodInDays: purchaseLookbackValues[options.Lookback],
+			AccountScope:         awstypes.AccountScopePayer,
+		}
+
+		for {
+			page, err := client.GetSavingsPlansPurchaseRecommendation(ctx, input)
+			if err != nil {
+				return types.CostData{}, classifyError(ctx, "GetSavingsPlansPurchaseRecommendation", err)
+			}
+
+			if recommendation := page.SavingsPlansPurchaseRecommendation; recommendation != nil {
+				for _, detail := range recommendation.SavingsPlansPurchaseRecommendationDetails {
+					if code := aws.ToString(detail.CurrencyCode); code != "" {
+						unit = code
+					}
+					plan := detail.SavingsPlansDetails
+					if plan == nil {
+						plan = &awstypes.SavingsPlansDetails{}
+					}
+
+					rows = append(rows, []types.Cell{
+						types.TextCell(planType.Label),
+						types.TextCell(aws.ToString(plan.Region)),
+						types.TextCell(aws.ToString(plan.InstanceFamily)),
+						types.ValueCell(parseAmount(detail.HourlyCommitmentToPurchase)),
+						types.ValueCell(parseAmount(detail.UpfrontCost)),
+						types.ValueCell(parseAmount(detail.EstimatedMonthlySavingsAmount)),
+						types.ValueCell(parseAmount(detail.EstimatedSavingsPercentage)),
+						optionalCell(detail.EstimatedAverageUtilization),
+						types.TextCell(aws.ToString(detail.AccountId)),
+					})
+					details = append(details, savingsPlansPurchaseDetails(planType.Label, options, *plan, detail, unit))
+				}
+			}
+
+			if page.NextPageToken == nil || *page.NextPageToken == "" {
+				break
+			}
+			input.NextPageToken = page.NextPageToken
+		}
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("🛒 Savings Plans Purchase Recommendations (%s)", options),
+		Columns: []types.Column{
+			{Title: "Plan Type"},
+			{Title: "Region"},
+			{Title: "Instance Family"},
+			{Title: "Hourly Commitment", Kind: types.KindMoney, Unit: unit},
+			{Title: PurchaseUpfrontColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: PurchaseSavingsColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: "Est. Savings", Kind: types.KindPercent},
+			{Title: "Est. Utilization", Kind: types.KindPercent, Threshold: utilizationThreshold},
+			{Title: "Account"},
+		},
+		Rows:    rows,
+		Details: details,
+	}, nil
+}
+
+// savingsPlansPurchaseDetails lists everything Cost Explorer says about a
+// recommended Savings Plan
+func savingsPlansPurchaseDetails(label string, options PurchaseOptions, plan awstypes.SavingsPlansDetails, detail awstypes.SavingsPlansPurchaseRecommendationDetail, unit string) []types.DetailField {
+	money := func(title string, amount *string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindMoney, Unit: unit}, Cell: optionalCell(amount)}
+	}
+	percent := func(title string, amount *string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindPercent}, Cell: optionalCell(amount)}
+	}
+	text := func(title, value string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title}, Cell: types.TextCell(value)}
+	}
+
+	return []types.DetailField{
+		text("Plan Type", label),
+		text("Options", options.String()),
+		text("Region", aws.ToString(plan.Region)),
+		text("Instance Family", aws.ToString(plan.InstanceFamily)),
+		text("Account", aws.ToString(detail.AccountId)),
+		text("Offering ID", aws.ToString(plan.OfferingId)),
+		money("Hourly Commitment", detail.HourlyCommitmentToPurchase),
+		money("Upfront Cost", detail.UpfrontCost),
+		money("Est. Plan Cost", detail.EstimatedSPCost),
+		money("Est. On-Demand Cost", detail.EstimatedOnDemandCost),
+		money("Est. Savings", detail.EstimatedSavingsAmount),
+		money("Est. Monthly Savings", detail.EstimatedMonthlySavingsAmount),
+		percent("Est. Savings Rate", detail.EstimatedSavingsPercentage),
+		percent("Est. Utilization", detail.EstimatedAverageUtilization),
+		percent("Est. ROI", detail.EstimatedROI),
+		money("Avg Hourly On-Demand", detail.CurrentAverageHourlyOnDemandSpend),
+		money("Min Hourly On-Demand", detail.CurrentMinimumHourlyOnDemandSpend),
+		money("Max Hourly On-Demand", detail.CurrentMaximumHourlyOnDemandSpend),
+	}
+}
+
+// reservationPurchases builds a row per recommended reservation of each
+// service Reserved Instances apply to
+func reservationPurchases(ctx context.Context, client types.CostExplorerAPI, options PurchaseOptions) (types.CostData, error) {
+	unit := "USD"
+	var rows [][]types.Cell
+	var details [][]types.DetailField
+
+	for _, service := range reservationServices {
+		input := &costexplorer.GetReservationPurchaseRecommendationInput{
+			Service:              aws.String(service),
+			TermInYears:          purchaseTermValues[options.Term],
+			PaymentOption:        purchasePaymentValues[options.Payment],
+			LookbackPeriodInDays: purchaseLookbackValues[options.Lookback],
+			AccountScope:         awstypes.AccountScopePayer,
+		}
+		if service == reservationServices[0] {
+			input.ServiceSpecification = &awstypes.ServiceSpecification{
+				EC2Specification: &awstypes.EC2Specification{OfferingClass: awstypes.OfferingClassStandard},
+			}
+		}
+
+		for {
+			page, err := client.GetReservationPurchaseRecommendation(ctx, input)
+			if err != nil {
+				return types.CostData{}, classifyError(ctx, "GetReservationPurchaseRecommendation", err)
+			}
+
+			for _, recommendation := range page.Recommendations {
+				for _, detail := range recommendation.RecommendationDetails {
+					if code := aws.ToString(detail.CurrencyCode); code != "" {
+						unit = code
+					}
+					instance := reservedInstance(detail.InstanceDetails)
+
+					rows = append(rows, []types.Cell{
+						types.TextCell(service),
+						types.TextCell(instance.Region),
+						types.TextCell(instance.Type),
+						types.ValueCell(parseAmount(detail.RecommendedNumberOfInstancesToPurchase)),
+						types.ValueCell(parseAmount(detail.RecurringStandardMonthlyCost) / hoursPerMonth),
+						types.ValueCell(parseAmount(detail.UpfrontCost)),
+						types.ValueCell(parseAmount(detail.EstimatedMonthlySavingsAmount)),
+						types.ValueCell(parseAmount(detail.EstimatedMonthlySavingsPercentage)),
+						optionalCell(detail.AverageUtilization),
+						types.TextCell(aws.ToString(detail.AccountId)),
+					})
+					details = append(details, reservationPurchaseDetails(service, options, instance, detail, unit))
+				}
+			}
+
+			if page.NextPageToken == nil || *page.NextPageToken == "" {
+				break
+			}
+			input.NextPageToken = page.NextPageToken
+		}
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("🛒 Reserved Instance Purchase Recommendations (%s)", options),
+		Columns: []types.Column{
+			{Title: "Service"},
+			{Title: "Region"},
+			{Title: "Instance Type"},
+			{Title: "Instances", Kind: types.KindQuantity},
+			{Title: "Hourly Commitment", Kind: types.KindMoney, Unit: unit},
+			{Title: PurchaseUpfrontColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: PurchaseSavingsColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: "Est. Savings", Kind: types.KindPercent},
+			{Title: "Avg Utilization", Kind: types.KindPercent},
+			{Title: "Account"},
+		},
+		Rows:    rows,
+		Details: details,
+	}, nil
+}
+
+// reservationPurchaseDetails lists everything Cost Explorer says about a
+// recommended reservation
+func reservationPurchaseDetails(service string, options PurchaseOptions, instance reservedInstanceDetails, detail awstypes.ReservationPurchaseRecommendationDetail, unit string) []types.DetailField {
+	money := func(title string, amount *string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindMoney, Unit: unit}, Cell: optionalCell(amount)}
+	}
+	quantity := func(title string, amount *string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindQuantity}, Cell: optionalCell(amount)}
+	}
+	percent := func(title string, amount *string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title, Kind: types.KindPercent}, Cell: optionalCell(amount)}
+	}
+	text := func(title, value string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: title}, Cell: types.TextCell(value)}
+	}
+
+	return []types.DetailField{
+		text("Service", service),
+		text("Options", options.String()),
+		text("Instance Type", instance.Type),
+		text("Region", instance.Region),
+		text("Platform", instance.Platform),
+		text("Account", aws.ToString(detail.AccountId)),
+		quantity("Instances to Buy", detail.RecommendedNumberOfInstancesToPurchase),
+		quantity("Normalized Units", detail.RecommendedNormalizedUnitsToPurchase),
+		money("Upfront Cost", detail.UpfrontCost),
+		money("Recurring Monthly", detail.RecurringStandardMonthlyCost),
+		money("Est. Monthly On-Demand", detail.EstimatedMonthlyOnDemandCost),
+		money("Est. Monthly Savings", detail.EstimatedMonthlySavingsAmount),
+		percent("Est. Savings Rate", detail.EstimatedMonthlySavingsPercentage),
+		quantity("Break-Even Months", detail.EstimatedBreakEvenInMonths),
+		percent("Avg Utilization", detail.AverageUtilization),
+		quantity("Avg Instances Used", detail.AverageNumberOfInstancesUsedPerHour),
+		quantity("Min Instances Used", detail.MinimumNumberOfInstancesUsedPerHour),
+		quantity("Max Instances Used", detail.MaximumNumberOfInstancesUsedPerHour),
+	}
+}
+
+// reservedInstanceDetails is the part of a reservation's instance details
+// that every service has
+type reservedInstanceDetails struct {
+	Type, Region, Platform string
+}
+
+// reservedInstance picks the instance details of whichever service the
+// reservation is for
+func reservedInstance(details *awstypes.InstanceDetails) reservedInstanceDetails {
+	switch {
+	case details == nil:
+		return reservedInstanceDetails{}
+	case details.EC2InstanceDetails != nil:
+		ec2 := details.EC2InstanceDetails
+		return reservedInstanceDetails{aws.ToString(ec2.InstanceType), aws.ToString(ec2.Region), aws.ToString(ec2.Platform)}
+	case details.RDSInstanceDetails != nil:
+		rds := details.RDSInstanceDetails
+		platform := strings.TrimSpace(aws.ToString(rds.DatabaseEngine) + " " + aws.ToString(rds.DeploymentOption))
+		return reservedInstanceDetails{aws.ToString(rds.InstanceType), aws.ToString(rds.Region), platform}
+	case details.ElastiCacheInstanceDetails != nil:
+		cache := details.ElastiCacheInstanceDetails
+		return reservedInstanceDetails{aws.ToString(cache.NodeType), aws.ToString(cache.Region), aws.ToString(cache.ProductDescription)}
+	case details.RedshiftInstanceDetails != nil:
+		redshift := details.RedshiftInstanceDetails
+		return reservedInstanceDetails{aws.ToString(redshift.NodeType), aws.ToString(redshift.Region), ""}
+	case details.ESInstanceDetails != nil:
+		search := details.ESInstanceDetails
+		return reservedInstanceDetails{aws.ToString(search.InstanceSize), aws.ToString(search.Region), ""}
+	case details.MemoryDBInstanceDetails != nil:
+		memory := details.MemoryDBInstanceDetails
+		return reservedInstanceDetails{aws.ToString(memory.NodeType), aws.ToString(memory.Region), ""}
+	}
+	return reservedInstanceDetails{}
+}
@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// reservationServices are the services Reserved Instances apply to. Coverage
+// cannot be grouped by service, so it is requested for each in turn.
+var reservationServices = []string{
+	"Amazon Elastic Compute Cloud - Compute",
+	"Amazon Relational Database Service",
+	"Amazon ElastiCache",
+	"Amazon Redshift",
+	"Amazon OpenSearch Service",
+}
+
+// GetReservationData fetches Reserved Instance utilization per subscription,
+// or coverage per service and region, over the query's range or its months
+// of history
+func GetReservationData(client types.CostExplorerAPI, q types.Query, mode string) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dateRange := throughToday(historyRange(q, time.Now()), time.Now())
+
+	var data types.CostData
+	var err error
+	if mode == CommitmentCoverage {
+		data, err = reservationCoverage(ctx, client, dateRange)
+	} else {
+		data, err = reservationUtilization(ctx, client, dateRange)
+	}
+
+	// Accounts without reservations have no data rather than zero utilization
+	if isDataUnavailable(err) {
+		return types.CostData{
+			Title:  "🎫 Reserved Instances",
+			Notice: "No Reserved Instance data for " + dateRange.String(),
+		}, nil
+	}
+	if err != nil {
+		return types.CostData{}, err
+	}
+
+	if !q.Filter.IsZero() {
+		data.Notice = filterIgnoredNotice("commitment utilization and coverage")
+	}
+	return data, nil
+}
+
+// reservationUtilization builds a row per subscription and a total row
+func reservationUtilization(ctx context.Context, client types.CostExplorerAPI, dateRange types.DateRange) (types.CostData, error) {
+	period := toInterval(dateRange)
+	// Granularity cannot be combined with GroupBy; the groups cover the whole range
+	input := &costexplorer.GetReservationUtilizationInput{
+		TimePeriod: &period,
+		GroupBy: []awstypes.GroupDefinition{
+			{Type: awstypes.GroupDefinitionTypeDimension, Key: aws.String("SUBSCRIPTION_ID")},
+		},
+	}
+
+	row := func(cells []types.Cell, utilization *awstypes.ReservationAggregates) []types.Cell {
+		if utilization == nil {
+			utilization = &awstypes.ReservationAggregates{}
+		}
+		return append(cells,
+			types.ValueCell(parseAmount(utilization.UtilizationPercentage)),
+			types.ValueCell(parseAmount(utilization.PurchasedHours)),
+			types.ValueCell(parseAmount(utilization.TotalActualHours)),
+			types.ValueCell(parseAmount(utilization.UnusedHours)),
+			types.ValueCell(parseAmount(utilization.RICostForUnusedHours)),
+			types.ValueCell(parseAmount(utilization.NetRISavings)),
+		)
+	}
+
+	var rows [][]types.Cell
+	var total *awstypes.ReservationAggregates
+	for {
+		page, err := client.GetReservationUtilization(ctx, input)
+		if err != nil {
+			return types.CostData{}, classifyError(ctx, "GetReservationUtilization", err)
+		}
+
+		for _, byTime := range page.UtilizationsByTime {
+			for _, group := range byTime.Groups {
+				rows = append(rows, row([]types.Cell{
+					types.TextCell(aws.ToString(group.Value)),
+					types.TextCell(attribute(group.Attributes, "instanceType")),
+					types.TextCell(attribute(group.Attributes, "region")),
+				}, group.Utilization))
+			}
+		}
+		if page.Total != nil {
+			total = page.Total
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	// The overall figures go in the title so the highlighting ranks subscriptions
+	title := fmt.Sprintf("🎫 Reserved Instance Utilization (%s)", dateRange)
+	if total != nil {
+		title += fmt.Sprintf(" - %.1f%% used overall", parseAmount(total.UtilizationPercentage))
+	}
+
+	return types.CostData{
+		Title: title,
+		Columns: []types.Column{
+			{Title: "Subscription"},
+			{Title: "Instance Type"},
+			{Title: "Region"},
+			{Title: "Utilization", Kind: types.KindPercent, Threshold: utilizationThreshold},
+			{Title: "Purchased Hours", Kind: types.KindQuantity},
+			{Title: "Used Hours", Kind: types.KindQuantity},
+			{Title: "Unused Hours", Kind: types.KindQuantity},
+			{Title: "Unused Cost", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "Net Savings", Kind: types.KindMoney, Unit: "USD"},
+		},
+		Rows:         rows,
+		HighlightTop: 3,
+	}, nil
+}
+
+// reservationCoverage builds a row per service and region, with the on-demand
+// hours that reservations could have covered
+func reservationCoverage(ctx context.Context, client types.CostExplorerAPI, dateRange types.DateRange) (types.CostData, error) {
+	period := toInterval(dateRange)
+
+	type CoverageData struct {
+		Service, Region         string
+		Reserved, OnDemand, All float64
+		OnDemandCost            float64
+	}
+	var groups []*CoverageData
+	var onDemandHours float64
+
+	for _, service := range reservationServices {
+		input := &costexplorer.GetReservationCoverageInput{
+			TimePeriod:  &period,
+			Granularity: awstypes.GranularityMonthly,
+			GroupBy: []awstypes.GroupDefinition{
+				{Type: awstypes.GroupDefinitionTypeDimension, Key: aws.String("REGION")},
+			},
+			Filter: &awstypes.Expression{Dimensions: &awstypes.DimensionValues{
+				Key:    awstypes.DimensionService,
+				Values: []string{service},
+			}},
+		}
+
+		// Sum the monthly coverage of each region
+		regions := make(map[string]*CoverageData)
+		for {
+			page, err := client.GetReservationCoverage(ctx, input)
+			if err != nil {
+				return types.CostData{}, classifyError(ctx, "GetReservationCoverage", err)
+			}
+
+			for _, byTime := range page.CoveragesByTime {
+				for _, group := range byTime.Groups {
+					if group.Coverage == nil || group.Coverage.CoverageHours == nil {
+						continue
+					}
+					region := attribute(group.Attributes, "region")
+					if regions[region] == nil {
+						regions[region] = &CoverageData{Service: normalizeServiceName(service), Region: region}
+					}
+					hours := group.Coverage.CoverageHours
+					regions[region].Reserved += parseAmount(hours.ReservedHours)
+					regions[region].OnDemand += parseAmount(hours.OnDemandHours)
+					regions[region].All += parseAmount(hours.TotalRunningHours)
+					if group.Coverage.CoverageCost != nil {
+						regions[region].OnDemandCost += parseAmount(group.Coverage.CoverageCost.OnDemandCost)
+					}
+				}
+			}
+
+			if page.NextPageToken == nil || *page.NextPageToken == "" {
+				break
+			}
+			input.NextPageToken = page.NextPageToken
+		}
+
+		for _, group := range regions {
+			if group.All > 0 {
+				groups = append(groups, group)
+				onDemandHours += group.OnDemand
+			}
+		}
+	}
+
+	// Most uncovered hours first, as those are the candidates for reservations
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].OnDemand != groups[j].OnDemand {
+			return groups[i].OnDemand > groups[j].OnDemand
+		}
+		return groups[i].Service+groups[i].Region < groups[j].Service+groups[j].Region
+	})
+
+	var rows [][]types.Cell
+	for _, group := range groups {
+		rows = append(rows, []types.Cell{
+			types.TextCell(group.Service),
+			types.TextCell(group.Region),
+			types.ValueCell(percentOf(group.Reserved, group.All)),
+			types.ValueCell(group.Reserved),
+			types.ValueCell(group.OnDemand),
+			types.ValueCell(group.All),
+			types.ValueCell(group.OnDemandCost),
+		})
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("🎫 Reserved Instance Coverage (%s) - %.0f on-demand hours could be covered", dateRange, onDemandHours),
+		Columns: []types.Column{
+			{Title: "Service"},
+			{Title: "Region"},
+			{Title: "Coverage", Kind: types.KindPercent, Threshold: coverageThreshold},
+			{Title: "Reserved Hours", Kind: types.KindQuantity},
+			{Title: "On-Demand Hours", Kind: types.KindQuantity},
+			{Title: "Total Hours", Kind: types.KindQuantity},
+			{Title: "On-Demand Cost", Kind: types.KindMoney, Unit: "USD"},
+		},
+		Rows:         rows,
+		HighlightTop: 3,
+	}, nil
+}
@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// rightsizingService is the service Cost Explorer makes rightsizing
+// recommendations for
+const rightsizingService = "AmazonEC2"
+
+// Columns of the rightsizing table the view sorts by
+const (
+	RightsizingSavingsColumn = "Est. Monthly Savings"
+	RightsizingCostColumn    = "Monthly Cost"
+	RightsizingCPUColumn     = "Max CPU"
+)
+
+// GetRightsizingData fetches the EC2 rightsizing and termination
+// recommendations, one row per instance with the full recommendation in its
+// details
+func GetRightsizingData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	input := &costexplorer.GetRightsizingRecommendationInput{
+		Service: aws.String(rightsizingService),
+		Configuration: &awstypes.RightsizingRecommendationConfiguration{
+			BenefitsConsidered:   true,
+			RecommendationTarget: awstypes.RecommendationTargetSameInstanceFamily,
+		},
+	}
+
+	var recommendations []awstypes.RightsizingRecommendation
+	var summary *awstypes.RightsizingRecommendationSummary
+	for {
+		page, err := client.GetRightsizingRecommendation(ctx, input)
+		if err != nil {
+			return types.CostData{}, classifyError(ctx, "GetRightsizingRecommendation", err)
+		}
+		recommendations = append(recommendations, page.RightsizingRecommendations...)
+		if page.Summary != nil {
+			summary = page.Summary
+		}
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	unit := "USD"
+	if summary != nil && aws.ToString(summary.SavingsCurrencyCode) != "" {
+		unit = aws.ToString(summary.SavingsCurrencyCode)
+	}
+
+	data := types.CostData{
+		Title: fmt.Sprintf("🔧 Rightsizing - %d recommendations", len(recommendations)),
+		Columns: []types.Column{
+			{Title: "Instance ID"},
+			{Title: "Name"},
+			{Title: "Action"},
+			{Title: "Current Type"},
+			{Title: "Target Type"},
+			{Title: RightsizingCostColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: RightsizingSavingsColumn, Kind: types.KindMoney, Unit: unit},
+			{Title: RightsizingCPUColumn, Kind: types.KindPercent},
+			{Title: "Max Memory", Kind: types.KindPercent},
+			{Title: "Region"},
+			{Title: "Account"},
+		},
+	}
+	if summary != nil && summary.SavingsPercentage != nil {
+		data.Title += fmt.Sprintf(", %.1f%% potential savings", parseAmount(summary.SavingsPercentage))
+	}
+
+	for _, recommendation := range recommendations {
+		row, details := rightsizingRow(recommendation, unit)
+		data.Rows = append(data.Rows, row)
+		data.Details = append(data.Details, details)
+	}
+
+	if !q.Filter.IsZero() {
+		data.Notice = filterIgnoredNotice("rightsizing recommendations")
+	}
+	if len(recommendations) == 0 {
+		data.Notice = "No rightsizing recommendations found; check they are enabled in the Cost Explorer preferences"
+	}
+	return data, nil
+}
+
+// rightsizingRow builds the table row and the details of a recommendation
+func rightsizingRow(recommendation awstypes.RightsizingRecommendation, unit string) ([]types.Cell, []types.DetailField) {
+	current := recommendation.CurrentInstance
+	if current == nil {
+		current = &awstypes.CurrentInstance{}
+	}
+	resource := ec2Details(current.ResourceDetails)
+	utilization := ec2Utilization(current.ResourceUtilization)
+
+	action := "Modify"
+	target := ""
+	var savings float64
+	var targets []awstypes.TargetInstance
+	if recommendation.RightsizingType == awstypes.RightsizingTypeTerminate {
+		action = "Terminate"
+		if detail := recommendation.TerminateRecommendationDetail; detail != nil {
+			savings = parseAmount(detail.EstimatedMonthlySavings)
+		}
+	} else if detail := recommendation.ModifyRecommendationDetail; detail != nil {
+		targets = detail.TargetInstances
+		if chosen, ok := defaultTarget(targets); ok {
+			target = aws.ToString(ec2Details(chosen.ResourceDetails).InstanceType)
+			savings = parseAmount(chosen.EstimatedMonthlySavings)
+		}
+	}
+
+	row := []types.Cell{
+		types.TextCell(aws.ToString(current.ResourceId)),
+		types.TextCell(aws.ToString(current.InstanceName)),
+		types.TextCell(action),
+		types.TextCell(aws.ToString(resource.InstanceType)),
+		types.TextCell(target),
+		types.ValueCell(parseAmount(current.MonthlyCost)),
+		types.ValueCell(savings),
+		optionalCell(utilization.MaxCpuUtilizationPercentage),
+		optionalCell(utilization.MaxMemoryUtilizationPercentage),
+		types.TextCell(aws.ToString(resource.Region)),
+		types.TextCell(aws.ToString(recommendation.AccountId)),
+	}
+
+	var findings []string
+	for _, code := range recommendation.FindingReasonCodes {
+		findings = append(findings, string(code))
+	}
+	var tags []string
+	for _, tag := range current.Tags {
+		tags = append(tags, aws.ToString(tag.Key)+"="+strings.Join(tag.Values, ","))
+	}
+
+	text := func(label, value string) types.DetailField {
+		return types.DetailField{Column: types.Column{Title: label}, Cell: types.TextCell(value)}
+	}
+	value := func(label string, kind types.ColumnKind, cell types.Cell) types.DetailField {
+		column := types.Column{Title: label, Kind: kind}
+		if kind == types.KindMoney {
+			column.Unit = unit
+		}
+		return types.DetailField{Column: column, Cell: cell}
+	}
+
+	details := []types.DetailField{
+		text("Instance ID", aws.ToString(current.ResourceId)),
+		text("Name", aws.ToString(current.InstanceName)),
+		text("Account", aws.ToString(recommendation.AccountId)),
+		text("Region", aws.ToString(resource.Region)),
+		text("Action", action),
+		text("Findings", strings.Join(findings, ", ")),
+		text("Current Type", aws.ToString(resource.InstanceType)),
+		text("Platform", aws.ToString(resource.Platform)),
+		text("vCPUs", aws.ToString(resource.Vcpu)),
+		text("Memory", aws.ToString(resource.Memory)),
+		value("Monthly Cost", types.KindMoney, types.ValueCell(parseAmount(current.MonthlyCost))),
+		value("Est. Monthly Savings", types.KindMoney, types.ValueCell(savings)),
+		value("Max CPU", types.KindPercent, optionalCell(utilization.MaxCpuUtilizationPercentage)),
+		value("Max Memory", types.KindPercent, optionalCell(utilization.MaxMemoryUtilizationPercentage)),
+		value("Max Storage", types.KindPercent, optionalCell(utilization.MaxStorageUtilizationPercentage)),
+		value("Running Hours", types.KindQuantity, optionalCell(current.TotalRunningHoursInLookbackPeriod)),
+		value("On-Demand Hours", types.KindQuantity, optionalCell(current.OnDemandHoursInLookbackPeriod)),
+		value("Reserved Hours", types.KindQuantity, optionalCell(current.ReservationCoveredHoursInLookbackPeriod)),
+		value("Savings Plans Hours", types.KindQuantity, optionalCell(current.SavingsPlansCoveredHoursInLookbackPeriod)),
+		text("Tags", strings.Join(tags, ", ")),
+	}
+
+	for i, option := range targets {
+		label := fmt.Sprintf("Option %d", i+1)
+		instanceType := aws.ToString(ec2Details(option.ResourceDetails).InstanceType)
+		if option.DefaultTargetInstance {
+			instanceType += " (recommended)"
+		}
+		expected := ec2Utilization(option.ExpectedResourceUtilization)
+		details = append(details,
+			text(label, instanceType),
+			value(label+" Cost", types.KindMoney, types.ValueCell(parseAmount(option.EstimatedMonthlyCost))),
+			value(label+" Savings", types.KindMoney, types.ValueCell(parseAmount(option.EstimatedMonthlySavings))),
+			value(label+" Expected CPU", types.KindPercent, optionalCell(expected.MaxCpuUtilizationPercentage)),
+		)
+	}
+
+	return row, details
+}
+
+// defaultTarget returns the target instance Cost Explorer recommends, or the
+// first one when none is marked as the default
+func defaultTarget(targets []awstypes.TargetInstance) (awstypes.TargetInstance, bool) {
+	for _, target := range targets {
+		if target.DefaultTargetInstance {
+			return target, true
+		}
+	}
+	if len(targets) > 0 {
+		return targets[0], true
+	}
+	return awstypes.TargetInstance{}, false
+}
+
+// ec2Details returns the EC2 details of a resource, empty when missing
+func ec2Details(details *awstypes.ResourceDetails) awstypes.EC2ResourceDetails {
+	if details == nil || details.EC2ResourceDetails == nil {
+		return awstypes.EC2ResourceDetails{}
+	}
+	return *details.EC2ResourceDetails
+}
+
+// ec2Utilization returns the EC2 utilization of a resource, empty when missing
+func ec2Utilization(utilization *awstypes.ResourceUtilization) awstypes.EC2ResourceUtilization {
+	if utilization == nil || utilization.EC2ResourceUtilization == nil {
+		return awstypes.EC2ResourceUtilization{}
+	}
+	return *utilization.EC2ResourceUtilization
+}
+
+// optionalCell parses a value that Cost Explorer may leave out, such as
+// memory utilization without the CloudWatch agent, as a blank cell
+func optionalCell(amount *string) types.Cell {
+	if amount == nil || *amount == "" {
+		return types.BlankCell()
+	}
+	return types.ValueCell(parseAmount(amount))
+}
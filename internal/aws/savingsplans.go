@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// The commitment views show either of these modes
+const (
+	CommitmentUtilization = "utilization"
+	CommitmentCoverage    = "coverage"
+)
+
+// CommitmentModes lists the commitment view modes in toggle order
+var CommitmentModes = []string{CommitmentUtilization, CommitmentCoverage}
+
+// utilizationThreshold colors utilization: unused commitment is money lost
+var utilizationThreshold = &types.Threshold{Good: 90, Warn: 70}
+
+// coverageThreshold colors coverage of eligible spend by commitments
+var coverageThreshold = &types.Threshold{Good: 80, Warn: 50}
+
+// GetSavingsPlansData fetches Savings Plans utilization per month, or coverage
+// by service and instance family, over the query's range or its months of
+// history
+func GetSavingsPlansData(client types.CostExplorerAPI, q types.Query, mode string) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	dateRange := throughToday(historyRange(q, time.Now()), time.Now())
+
+	var data types.CostData
+	var err error
+	if mode == CommitmentCoverage {
+		data, err = savingsPlansCoverage(ctx, client, dateRange)
+	} else {
+		data, err = savingsPlansUtilization(ctx, client, dateRange)
+	}
+
+	// Accounts without Savings Plans have no data rather than zero utilization
+	if isDataUnavailable(err) {
+		return types.CostData{
+			Title:  "💰 Savings Plans",
+			Notice: "No Savings Plans data for " + dateRange.String(),
+		}, nil
+	}
+	if err != nil {
+		return types.CostData{}, err
+	}
+
+	if !q.Filter.IsZero() {
+		data.Notice = filterIgnoredNotice("commitment utilization and coverage")
+	}
+	return data, nil
+}
+
+// savingsPlansUtilization builds a row per month and a total row
+func savingsPlansUtilization(ctx context.Context, client types.CostExplorerAPI, dateRange types.DateRange) (types.CostData, error) {
+	period := toInterval(dateRange)
+	result, err := client.GetSavingsPlansUtilization(ctx, &costexplorer.GetSavingsPlansUtilizationInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetSavingsPlansUtilization", err)
+	}
+
+	row := func(label string, utilization *awstypes.SavingsPlansUtilization, savings *awstypes.SavingsPlansSavings) []types.Cell {
+		if utilization == nil {
+			utilization = &awstypes.SavingsPlansUtilization{}
+		}
+		if savings == nil {
+			savings = &awstypes.SavingsPlansSavings{}
+		}
+		return []types.Cell{
+			types.TextCell(label),
+			types.ValueCell(parseAmount(utilization.UtilizationPercentage)),
+			types.ValueCell(parseAmount(utilization.TotalCommitment)),
+			types.ValueCell(parseAmount(utilization.UsedCommitment)),
+			types.ValueCell(parseAmount(utilization.UnusedCommitment)),
+			types.ValueCell(parseAmount(savings.NetSavings)),
+			types.ValueCell(parseAmount(savings.OnDemandCostEquivalent)),
+		}
+	}
+
+	var rows [][]types.Cell
+	for _, byTime := range result.SavingsPlansUtilizationsByTime {
+		rows = append(rows, row(periodLabel(byTime.TimePeriod), byTime.Utilization, byTime.Savings))
+	}
+	if result.Total != nil && len(rows) > 1 {
+		rows = append(rows, row("Total", result.Total.Utilization, result.Total.Savings))
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("💰 Savings Plans Utilization (%s)", dateRange),
+		Columns: []types.Column{
+			{Title: "Period"},
+			{Title: "Utilization", Kind: types.KindPercent, Threshold: utilizationThreshold},
+			{Title: "Commitment", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "Used", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "Unused", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "Net Savings", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "On-Demand Equivalent", Kind: types.KindMoney, Unit: "USD"},
+		},
+		Rows: rows,
+	}, nil
+}
+
+// savingsPlansCoverage builds a row per service and instance family, with the
+// overall coverage in the title
+func savingsPlansCoverage(ctx context.Context, client types.CostExplorerAPI, dateRange types.DateRange) (types.CostData, error) {
+	period := toInterval(dateRange)
+	input := &costexplorer.GetSavingsPlansCoverageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+		GroupBy: []awstypes.GroupDefinition{
+			{Type: awstypes.GroupDefinitionTypeDimension, Key: aws.String("SERVICE")},
+			{Type: awstypes.GroupDefinitionTypeDimension, Key: aws.String("INSTANCE_FAMILY")},
+		},
+	}
+
+	// Sum the monthly coverage of each service and instance family
+	type CoverageData struct {
+		Service, Family        string
+		Covered, OnDemand, All float64
+	}
+	coverage := make(map[string]*CoverageData)
+	var covered, total float64
+
+	for {
+		page, err := client.GetSavingsPlansCoverage(ctx, input)
+		if err != nil {
+			return types.CostData{}, classifyError(ctx, "GetSavingsPlansCoverage", err)
+		}
+
+		for _, item := range page.SavingsPlansCoverages {
+			if item.Coverage == nil {
+				continue
+			}
+			service := normalizeServiceName(attribute(item.Attributes, "SERVICE"))
+			family := attribute(item.Attributes, "INSTANCE_FAMILY")
+			key := service + "|" + family
+			if coverage[key] == nil {
+				coverage[key] = &CoverageData{Service: service, Family: family}
+			}
+			group := coverage[key]
+			group.Covered += parseAmount(item.Coverage.SpendCoveredBySavingsPlans)
+			group.OnDemand += parseAmount(item.Coverage.OnDemandCost)
+			group.All += parseAmount(item.Coverage.TotalCost)
+			covered += parseAmount(item.Coverage.SpendCoveredBySavingsPlans)
+			total += parseAmount(item.Coverage.TotalCost)
+		}
+
+		if page.NextToken == nil || *page.NextToken == "" {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	var groups []*CoverageData
+	for _, group := range coverage {
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].All != groups[j].All {
+			return groups[i].All > groups[j].All
+		}
+		return groups[i].Service+groups[i].Family < groups[j].Service+groups[j].Family
+	})
+
+	var rows [][]types.Cell
+	for _, group := range groups {
+		rows = append(rows, []types.Cell{
+			types.TextCell(group.Service),
+			types.TextCell(group.Family),
+			types.ValueCell(percentOf(group.Covered, group.All)),
+			types.ValueCell(group.Covered),
+			types.ValueCell(group.OnDemand),
+			types.ValueCell(group.All),
+		})
+	}
+
+	return types.CostData{
+		Title: fmt.Sprintf("💰 Savings Plans Coverage (%s) - %.1f%% covered", dateRange, percentOf(covered, total)),
+		Columns: []types.Column{
+			{Title: "Service"},
+			{Title: "Instance Family"},
+			{Title: "Coverage", Kind: types.KindPercent, Threshold: coverageThreshold},
+			{Title: "Covered", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "On-Demand", Kind: types.KindMoney, Unit: "USD"},
+			{Title: "Total", Kind: types.KindMoney, Unit: "USD"},
+		},
+		Rows: rows,
+	}, nil
+}
+
+// attribute looks up a coverage attribute by dimension name. The API returns
+// the attribute names in varying case, e.g. "instanceFamily" for
+// INSTANCE_FAMILY, so the comparison ignores case and underscores.
+func attribute(attributes map[string]string, dimension string) string {
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+	for name, value := range attributes {
+		if normalize(name) == normalize(dimension) {
+			return value
+		}
+	}
+	return ""
+}
+
+// percentOf returns part as a percentage of whole, or zero for an empty whole
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return part / whole * 100
+}
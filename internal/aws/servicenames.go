@@ -0,0 +1,120 @@
+package aws
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+
+	"cost-explorer/internal/config"
+)
+
+// AliasMarker follows the label of a row combining several raw service names
+const AliasMarker = " *"
+
+// defaultServiceNameRules map service names returned by Cost Explorer to the
+// names the AWS console shows. EC2-Other stays separate: besides data
+// transfer it holds EBS volumes, snapshots and NAT gateways.
+var defaultServiceNameRules = []config.ServiceNameRule{
+	{Match: "Amazon Elastic Compute Cloud - Compute", Name: "EC2-Instances"},
+	{Match: "EC2 - Other", Name: "EC2-Other"},
+	{Match: "Amazon EC2 Container Service", Name: "Elastic Container Service"},
+	{Match: "Amazon Elastic Container Service", Name: "Elastic Container Service"},
+	{Match: "Amazon Virtual Private Cloud", Name: "VPC"},
+	{Match: "Amazon Simple Storage Service", Name: "S3"},
+	{Match: "AWS Data Transfer", Name: "Data Transfer"},
+	{Pattern: `^(?:Amazon|AWS) (.+)$`, Name: "$1"},
+}
+
+// serviceNameRule is a config.ServiceNameRule with its pattern compiled
+type serviceNameRule struct {
+	match   string
+	pattern *regexp.Regexp
+	name    string
+}
+
+var (
+	serviceNameMu    sync.RWMutex
+	serviceNameRules = mustCompileServiceNameRules(defaultServiceNameRules)
+)
+
+// SetServiceNames replaces the rules used to name services, e.g. with those
+// from the user config
+func SetServiceNames(names config.ServiceNames) error {
+	var rules []config.ServiceNameRule
+	if !names.Raw {
+		rules = append(rules, names.Rules...)
+		if !names.NoDefaults {
+			rules = append(rules, defaultServiceNameRules...)
+		}
+	}
+
+	compiled, err := compileServiceNameRules(rules)
+	if err != nil {
+		return err
+	}
+
+	serviceNameMu.Lock()
+	serviceNameRules = compiled
+	serviceNameMu.Unlock()
+	return nil
+}
+
+// compileServiceNameRules checks that each rule has one kind of match and
+// compiles its pattern
+func compileServiceNameRules(rules []config.ServiceNameRule) ([]serviceNameRule, error) {
+	compiled := make([]serviceNameRule, 0, len(rules))
+	for i, rule := range rules {
+		if (rule.Match == "") == (rule.Pattern == "") {
+			return nil, fmt.Errorf("service name rule %d needs exactly one of match or pattern", i+1)
+		}
+
+		c := serviceNameRule{match: rule.Match, name: rule.Name}
+		if rule.Pattern != "" {
+			pattern, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("service name rule %d: %w", i+1, err)
+			}
+			c.pattern = pattern
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// mustCompileServiceNameRules is compileServiceNameRules for the built-in rules
+func mustCompileServiceNameRules(rules []config.ServiceNameRule) []serviceNameRule {
+	compiled, err := compileServiceNameRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return compiled
+}
+
+// normalizeServiceName names a service by the first rule matching it, keeping
+// the raw name when none does
+func normalizeServiceName(serviceName string) string {
+	serviceNameMu.RLock()
+	defer serviceNameMu.RUnlock()
+
+	for _, rule := range serviceNameRules {
+		if rule.pattern == nil {
+			if rule.match != serviceName {
+				continue
+			}
+			if rule.name == "" {
+				return serviceName
+			}
+			return rule.name
+		}
+
+		match := rule.pattern.FindStringSubmatchIndex(serviceName)
+		if match == nil {
+			continue
+		}
+		if rule.name == "" {
+			return serviceName
+		}
+		return string(rule.pattern.ExpandString(nil, rule.name, serviceName, match))
+	}
+	return serviceName
+}
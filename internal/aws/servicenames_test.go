@@ -0,0 +1,77 @@
+package aws
+
+import (
+	"testing"
+
+	"cost-explorer/internal/config"
+)
+
+// useServiceNames applies service name settings for the rest of the test
+func useServiceNames(t *testing.T, names config.ServiceNames) {
+	t.Helper()
+	if err := SetServiceNames(names); err != nil {
+		t.Fatalf("SetServiceNames: %v", err)
+	}
+	t.Cleanup(func() { SetServiceNames(config.ServiceNames{}) })
+}
+
+func TestNormalizeServiceName(t *testing.T) {
+	userRules := []config.ServiceNameRule{
+		{Match: "Amazon Relational Database Service", Name: "RDS"},
+		{Match: "AWS Lambda", Name: ""},
+		{Pattern: `^Amazon Elastic (.+) Service$`, Name: "E$1"},
+	}
+
+	tests := []struct {
+		name    string
+		names   config.ServiceNames
+		service string
+		want    string
+	}{
+		{"default exact", config.ServiceNames{}, "Amazon Simple Storage Service", "S3"},
+		{"default pattern", config.ServiceNames{}, "Amazon Relational Database Service", "Relational Database Service"},
+		{"default AWS prefix", config.ServiceNames{}, "AWS Key Management Service", "Key Management Service"},
+		{"default no match", config.ServiceNames{}, "Tax", "Tax"},
+		{"exact before defaults", config.ServiceNames{Rules: userRules}, "Amazon Relational Database Service", "RDS"},
+		{"empty name keeps raw", config.ServiceNames{Rules: userRules}, "AWS Lambda", "AWS Lambda"},
+		{"pattern group", config.ServiceNames{Rules: userRules}, "Amazon Elastic Container Service", "EContainer"},
+		{"defaults still apply", config.ServiceNames{Rules: userRules}, "Amazon Simple Storage Service", "S3"},
+		{"no defaults", config.ServiceNames{Rules: userRules, NoDefaults: true}, "Amazon Simple Storage Service", "Amazon Simple Storage Service"},
+		{"no defaults user rule", config.ServiceNames{Rules: userRules, NoDefaults: true}, "Amazon Relational Database Service", "RDS"},
+		{"raw", config.ServiceNames{Rules: userRules, Raw: true}, "Amazon Relational Database Service", "Amazon Relational Database Service"},
+		{"raw ignores defaults", config.ServiceNames{Raw: true}, "Amazon Simple Storage Service", "Amazon Simple Storage Service"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useServiceNames(t, tt.names)
+			if got := normalizeServiceName(tt.service); got != tt.want {
+				t.Errorf("normalizeServiceName(%q) = %q, want %q", tt.service, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetServiceNamesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rule config.ServiceNameRule
+	}{
+		{"invalid pattern", config.ServiceNameRule{Pattern: "(", Name: "x"}},
+		{"match and pattern", config.ServiceNameRule{Match: "S3", Pattern: "S3", Name: "x"}},
+		{"neither", config.ServiceNameRule{Name: "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useServiceNames(t, config.ServiceNames{})
+			if err := SetServiceNames(config.ServiceNames{Rules: []config.ServiceNameRule{tt.rule}}); err == nil {
+				t.Fatalf("SetServiceNames succeeded, want an error")
+			}
+			// A rejected config leaves the previous rules in place
+			if got := normalizeServiceName("Amazon Simple Storage Service"); got != "S3" {
+				t.Errorf("rules changed after an error: got %q, want S3", got)
+			}
+		})
+	}
+}
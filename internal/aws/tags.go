@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// UntaggedLabel is the row label for spend without a value for the tag key
+const UntaggedLabel = "untagged"
+
+// GetTagKeys lists the cost allocation tag keys with spend in the query's
+// range, or its months of history, sorted by name
+func GetTagKeys(client types.CostExplorerAPI, q types.Query) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	period := toInterval(historyRange(q, time.Now()))
+	input := &costexplorer.GetTagsInput{TimePeriod: &period}
+
+	var keys []string
+	for {
+		page, err := client.GetTags(ctx, input)
+		if err != nil {
+			return nil, classifyError(ctx, "GetTags", err)
+		}
+		keys = append(keys, page.Tags...)
+
+		if page.NextPageToken == nil || *page.NextPageToken == "" {
+			break
+		}
+		input.NextPageToken = page.NextPageToken
+	}
+
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// GetTagData fetches costs grouped by the values of a cost allocation tag,
+// with spend that has no value for the tag shown as an untagged row
+func GetTagData(client types.CostExplorerAPI, q types.Query, tagKey string) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	dateRange := rangeOrDefault(q, currentMonthRange(time.Now()))
+	period := toInterval(dateRange)
+
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityMonthly,
+		Metrics:     []string{metric},
+		Filter:      queryFilter(q),
+		GroupBy: []awstypes.GroupDefinition{
+			{
+				Type: awstypes.GroupDefinitionTypeTag,
+				Key:  &tagKey,
+			},
+		},
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	costGroups, unit := groupTotals(result.ResultsByTime, metric, func(key string) string {
+		return groupValue(key, UntaggedLabel)
+	})
+
+	rows := percentageRows(costGroups)
+
+	return types.CostData{
+		Title: fmt.Sprintf("🏷️ Tag: %s", tagKey),
+		Columns: []types.Column{
+			{Title: tagKey},
+			{Title: fmt.Sprintf("Cost (%s)", dateRange), Kind: types.KindMoney, Unit: unit},
+			{Title: "Percentage", Kind: types.KindPercent},
+		},
+		Rows: rows,
+	}, nil
+}
+
+// groupValue extracts the value from a tag or cost category group key. Cost
+// Explorer returns these keys as "name$value", with an empty value for spend
+// without one, which is labelled empty instead.
+func groupValue(groupKey, empty string) string {
+	value := groupKey
+	if index := strings.Index(groupKey, "$"); index >= 0 {
+		value = groupKey[index+1:]
+	}
+	if value == "" {
+		return empty
+	}
+	return value
+}
@@ -0,0 +1,216 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+// timeSeriesTopServices is how many services get their own column in the
+// daily and hourly views
+const timeSeriesTopServices = 5
+
+// HourlyWindows are the selectable look-back windows of the hourly view, in hours
+var HourlyWindows = []int{24, 48, 72}
+
+// GetDailyData fetches per-day costs over the selected range, the last 30 days
+// by default, with the day's total, its change from the previous day and the
+// top services as columns
+func GetDailyData(client types.CostExplorerAPI, q types.Query) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	defaultRange, _ := PresetRange("30d", time.Now())
+	dateRange := rangeOrDefault(q, defaultRange)
+	period := toInterval(dateRange)
+
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityDaily,
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q),
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeDimension,
+			Key:  &[]string{"SERVICE"}[0],
+		}},
+	})
+
+	if err != nil {
+		return types.CostData{}, classifyError(ctx, "GetCostAndUsage", err)
+	}
+
+	data := timeSeriesData(result.ResultsByTime, metric, "Date", func(start string) string {
+		return start
+	})
+	data.Title = fmt.Sprintf("📆 Daily Costs (%s)", dateRange)
+	return data, nil
+}
+
+// GetHourlyData fetches per-hour costs by service for the last hours. Hourly
+// data has to be enabled in the Cost Explorer preferences; when it is not,
+// the last days are shown at daily granularity with a notice instead.
+func GetHourlyData(client types.CostExplorerAPI, q types.Query, hours int) (types.CostData, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	metric := queryMetric(q.Metric)
+
+	// Hourly periods use full timestamps; the current hour is still incomplete
+	end := time.Now().UTC().Truncate(time.Hour)
+	start := end.Add(-time.Duration(hours) * time.Hour)
+	period := awstypes.DateInterval{
+		Start: aws.String(start.Format(time.RFC3339)),
+		End:   aws.String(end.Format(time.RFC3339)),
+	}
+
+	result, err := getAllCostAndUsage(ctx, client, &costexplorer.GetCostAndUsageInput{
+		TimePeriod:  &period,
+		Granularity: awstypes.GranularityHourly,
+		Metrics:     []string{metric},
+		Filter:      totalsFilter(q),
+		GroupBy: []awstypes.GroupDefinition{{
+			Type: awstypes.GroupDefinitionTypeDimension,
+			Key:  &[]string{"SERVICE"}[0],
+		}},
+	})
+
+	if err != nil {
+		fetchErr := classifyError(ctx, "GetCostAndUsage", err)
+		if errorKind(ctx, err) != ErrorHourlyNotEnabled {
+			return types.CostData{}, fetchErr
+		}
+
+		// Fall back to daily data covering the same window
+		days := (hours + 23) / 24
+		fallback := types.DateRange{
+			Start: today(end).AddDate(0, 0, 1-days),
+			End:   today(end).AddDate(0, 0, 1),
+			Label: fmt.Sprintf("Last %d days", days),
+		}
+		daily := q
+		daily.Range = fallback
+		data, dailyErr := GetDailyData(client, daily)
+		if dailyErr != nil {
+			return types.CostData{}, fetchErr
+		}
+		data.Notice = fmt.Sprintf("%s - %s. Showing daily costs instead.", ErrorHourlyNotEnabled, ErrorHourlyNotEnabled.Hint())
+		return data, nil
+	}
+
+	data := timeSeriesData(result.ResultsByTime, metric, "Hour (UTC)", func(start string) string {
+		if parsed, err := time.Parse(time.RFC3339, start); err == nil {
+			return parsed.Format("Jan 02 15:04")
+		}
+		return start
+	})
+	data.Title = fmt.Sprintf("⏱️ Hourly Costs (last %d hours)", hours)
+	return data, nil
+}
+
+// timeSeriesData builds a table with one row per period, newest first: the
+// period's total, its change from the previous period and the top services
+func timeSeriesData(results []awstypes.ResultByTime, metric, periodTitle string, label func(start string) string) types.CostData {
+	// Costs per period and service, plus service totals to pick the top services
+	type PeriodData struct {
+		Start    string
+		Total    float64
+		Services map[string]float64
+	}
+
+	var periods []PeriodData
+	serviceTotals := make(map[string]float64)
+	unit := ""
+	for _, resultByTime := range results {
+		period := PeriodData{Start: aws.ToString(resultByTime.TimePeriod.Start), Services: make(map[string]float64)}
+
+		for _, group := range resultByTime.Groups {
+			if len(group.Keys) == 0 || group.Metrics == nil {
+				continue
+			}
+			serviceName := normalizeServiceName(group.Keys[0])
+
+			if amount, amountUnit, exists := metricAmount(group.Metrics, metric); exists && amount != 0 {
+				period.Services[serviceName] += amount
+				period.Total += amount
+				serviceTotals[serviceName] += amount
+				unit = amountUnit
+			}
+		}
+
+		periods = append(periods, period)
+	}
+
+	// Order periods chronologically so each can be compared with the one before
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Start < periods[j].Start
+	})
+
+	var topServices []types.CostGroup
+	for serviceName, amount := range serviceTotals {
+		topServices = append(topServices, types.CostGroup{Name: serviceName, Amount: amount})
+	}
+	sort.Slice(topServices, func(i, j int) bool {
+		return topServices[i].Amount > topServices[j].Amount
+	})
+	if len(topServices) > timeSeriesTopServices {
+		topServices = topServices[:timeSeriesTopServices]
+	}
+
+	columns := []types.Column{
+		{Title: periodTitle},
+		{Title: "Total", Kind: types.KindMoney, Unit: unit},
+		{Title: "Change", Kind: types.KindMoney, Unit: unit},
+		{Title: "Change %", Kind: types.KindPercent},
+	}
+	for _, service := range topServices {
+		columns = append(columns, types.Column{Title: service.Name, Kind: types.KindMoney, Unit: unit})
+	}
+	columns = append(columns, types.Column{Title: "Other", Kind: types.KindMoney, Unit: unit})
+
+	// Build rows newest first so the latest spend is at the top
+	var rows [][]types.Cell
+	for i := len(periods) - 1; i >= 0; i-- {
+		period := periods[i]
+
+		// The oldest period has nothing to compare with
+		change, changePercent := types.BlankCell(), types.BlankCell()
+		if i > 0 {
+			previous := periods[i-1].Total
+			change = types.ValueCell(period.Total - previous)
+			if previous != 0 {
+				changePercent = types.ValueCell((period.Total - previous) / previous * 100)
+			}
+		}
+
+		row := []types.Cell{
+			types.TextCell(label(period.Start)),
+			types.ValueCell(period.Total),
+			change,
+			changePercent,
+		}
+
+		other := period.Total
+		for _, service := range topServices {
+			row = append(row, types.ValueCell(period.Services[service.Name]))
+			other -= period.Services[service.Name]
+		}
+		row = append(row, types.ValueCell(other))
+
+		rows = append(rows, row)
+	}
+
+	return types.CostData{
+		Columns:      columns,
+		Rows:         rows,
+		HighlightTop: 3,
+	}
+}
@@ -0,0 +1,91 @@
+package aws
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"cost-explorer/internal/types"
+
+	awstypes "github.com/aws/aws-sdk-go-v2/service/costexplorer/types"
+)
+
+func TestTimeSeriesData(t *testing.T) {
+	start := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	dateRange := types.DateRange{Start: start, End: start.AddDate(0, 0, 5)}
+	results := fakeResults(t, NewFakeClient(), awstypes.GranularityDaily, dateRange, "SERVICE")
+
+	data := timeSeriesData(results, DefaultMetric, "Date", func(start string) string { return start })
+
+	if len(data.Rows) != 5 {
+		t.Fatalf("got %d rows, want 5", len(data.Rows))
+	}
+	// Period, Total, Change, Change %, the top services and Other
+	if got, want := len(data.Columns), 4+timeSeriesTopServices+1; got != want {
+		t.Fatalf("got %d columns, want %d", got, want)
+	}
+	if got := data.Rows[0][0].Text; got != "2025-03-14" {
+		t.Errorf("first row %q, want the newest day", got)
+	}
+
+	for i, row := range data.Rows {
+		total := row[1].Value
+
+		// Each day's change is from the day before, the next row down; the
+		// oldest day has none
+		if i == len(data.Rows)-1 {
+			if !row[2].Blank || !row[3].Blank {
+				t.Errorf("%s: oldest day shows a change", row[0].Text)
+			}
+		} else if wantChange := total - data.Rows[i+1][1].Value; row[2].Blank || math.Abs(row[2].Value-wantChange) > 0.001 {
+			t.Errorf("%s: change %.2f, want %.2f", row[0].Text, row[2].Value, wantChange)
+		}
+
+		// The service columns and Other add up to the total
+		var sum float64
+		for _, cell := range row[4:] {
+			sum += cell.Value
+		}
+		if math.Abs(sum-total) > 0.001 {
+			t.Errorf("%s: services sum to %.2f, total is %.2f", row[0].Text, sum, total)
+		}
+	}
+}
+
+func TestGetHourlyDataFallsBackToDaily(t *testing.T) {
+	client := NewFakeClient()
+	client.HourlyDisabled = true
+
+	data, err := GetHourlyData(client, types.Query{}, 48)
+	if err != nil {
+		t.Fatalf("GetHourlyData: %v", err)
+	}
+	if !strings.Contains(data.Notice, "daily") {
+		t.Errorf("notice %q does not mention the daily fallback", data.Notice)
+	}
+	if len(data.Rows) != 2 {
+		t.Errorf("got %d rows, want 2 days", len(data.Rows))
+	}
+}
+
+func TestGetHourlyDataFallbackKeepsQuery(t *testing.T) {
+	client := NewFakeClient()
+	client.HourlyDisabled = true
+
+	all, err := GetHourlyData(client, types.Query{}, 48)
+	if err != nil {
+		t.Fatalf("GetHourlyData: %v", err)
+	}
+	usage, err := GetHourlyData(client, types.Query{ExcludedRecordTypes: []string{"Credit", "Refund", "Tax"}}, 48)
+	if err != nil {
+		t.Fatalf("GetHourlyData with excluded record types: %v", err)
+	}
+
+	if len(all.Rows) == 0 || len(usage.Rows) != len(all.Rows) {
+		t.Fatalf("got %d and %d rows, want the same number of days", len(all.Rows), len(usage.Rows))
+	}
+	if usage.Rows[0][1].Value == all.Rows[0][1].Value {
+		t.Errorf("daily fallback ignored the excluded record types: both cost %.2f", all.Rows[0][1].Value)
+	}
+}
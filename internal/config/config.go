@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileName is the name of the user config file in the config directory
+const FileName = "config.json"
+
+// Config holds the user settings read from the config file
+type Config struct {
+	ServiceNames ServiceNames `json:"service_names"`
+	Currency     Currency     `json:"currency"`
+}
+
+// Currency controls how money amounts are formatted. Amounts are shown in the
+// currency Cost Explorer returns them in unless a reporting currency is set
+// and has a rate for it.
+type Currency struct {
+	Locale    string             `json:"locale"`    // Separators and symbol placement, e.g. "de-DE"; "en-US" by default
+	Compact   bool               `json:"compact"`   // Abbreviate large amounts, e.g. "$12.3k"
+	Reporting string             `json:"reporting"` // Currency code to convert amounts to, e.g. "EUR"
+	Rates     map[string]float64 `json:"rates"`     // Reporting currency per unit of each billed currency, e.g. {"USD": 0.92}
+}
+
+// ServiceNames controls how service names returned by Cost Explorer are
+// shown. User rules are tried before the defaults, which match the names in
+// the AWS console.
+type ServiceNames struct {
+	Raw        bool              `json:"raw"`         // Show names as returned, ignoring every rule
+	NoDefaults bool              `json:"no_defaults"` // Apply only the user rules
+	Rules      []ServiceNameRule `json:"rules"`
+}
+
+// ServiceNameRule renames services matching either an exact name or a
+// regular expression. Name may refer to the pattern's groups, e.g. "$1"; an
+// empty name keeps the raw name and stops later rules from applying.
+type ServiceNameRule struct {
+	Match   string `json:"match,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Name    string `json:"name"`
+}
+
+// Dir returns the config directory, under XDG_CONFIG_HOME when set
+func Dir() (string, error) {
+	// Check for XDG_CONFIG_HOME first
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "cost-explorer"), nil
+	}
+
+	// Fall back to ~/.config on Unix-like systems
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, ".config", "cost-explorer"), nil
+}
+
+// Load reads the config file from the config directory. A missing file gives
+// the default config.
+func Load() (Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Config{}, err
+	}
+	return LoadFile(filepath.Join(dir, FileName))
+}
+
+// LoadFile reads a config file, returning the default config when it does
+// not exist
+func LoadFile(path string) (Config, error) {
+	var cfg Config
+	contents, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(contents, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -2,7 +2,11 @@
 package types
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/costexplorer"
 	"github.com/rivo/tview"
@@ -10,16 +14,191 @@ import (
 
 // AppState holds the main application state
 type AppState struct {
-	App        *tview.Application
-	Grid       *tview.Grid
-	Menu       *tview.List
-	MainTable  *tview.Table
-	Header     *tview.TextView
-	Footer     *tview.TextView
-	Client     *costexplorer.Client
-	Loading    bool
-	DataCache  map[string]CostData
-	CacheMutex sync.RWMutex
+	App            *tview.Application
+	Pages          *tview.Pages
+	Grid           *tview.Grid
+	Menu           *tview.List
+	MainTable      *tview.Table
+	DetailPane     *tview.TextView
+	Content        *tview.Flex
+	Header         *tview.TextView
+	Footer         *tview.TextView
+	StatusBar      *tview.TextView
+	Client         CostExplorerAPI                               // Client of the current profile
+	Clients        map[string]CostExplorerAPI                    // Clients by profile, guarded by CacheMutex
+	NewClient      func(profile string) (CostExplorerAPI, error) // Creates the client of a profile switched to
+	Query          Query
+	Loading        bool
+	CurrentSection string
+	DataCache      map[string]CostData
+	ErrorCache     map[string]error
+	Fetching       map[string]bool // Cache keys with a fetch in flight
+	CacheMutex     sync.RWMutex
+}
+
+// CostExplorerAPI is the subset of the Cost Explorer client used by the app.
+// *costexplorer.Client satisfies it; aws.FakeClient provides canned responses.
+type CostExplorerAPI interface {
+	GetAnomalies(ctx context.Context, params *costexplorer.GetAnomaliesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetAnomaliesOutput, error)
+	GetAnomalyMonitors(ctx context.Context, params *costexplorer.GetAnomalyMonitorsInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetAnomalyMonitorsOutput, error)
+	GetCostAndUsage(ctx context.Context, params *costexplorer.GetCostAndUsageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostAndUsageOutput, error)
+	GetDimensionValues(ctx context.Context, params *costexplorer.GetDimensionValuesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetDimensionValuesOutput, error)
+	GetCostForecast(ctx context.Context, params *costexplorer.GetCostForecastInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostForecastOutput, error)
+	GetCostCategories(ctx context.Context, params *costexplorer.GetCostCategoriesInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetCostCategoriesOutput, error)
+	GetRightsizingRecommendation(ctx context.Context, params *costexplorer.GetRightsizingRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetRightsizingRecommendationOutput, error)
+	GetReservationCoverage(ctx context.Context, params *costexplorer.GetReservationCoverageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationCoverageOutput, error)
+	GetReservationPurchaseRecommendation(ctx context.Context, params *costexplorer.GetReservationPurchaseRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationPurchaseRecommendationOutput, error)
+	GetReservationUtilization(ctx context.Context, params *costexplorer.GetReservationUtilizationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetReservationUtilizationOutput, error)
+	GetSavingsPlansCoverage(ctx context.Context, params *costexplorer.GetSavingsPlansCoverageInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansCoverageOutput, error)
+	GetSavingsPlansPurchaseRecommendation(ctx context.Context, params *costexplorer.GetSavingsPlansPurchaseRecommendationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansPurchaseRecommendationOutput, error)
+	GetSavingsPlansUtilization(ctx context.Context, params *costexplorer.GetSavingsPlansUtilizationInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetSavingsPlansUtilizationOutput, error)
+	GetTags(ctx context.Context, params *costexplorer.GetTagsInput, optFns ...func(*costexplorer.Options)) (*costexplorer.GetTagsOutput, error)
+	ProvideAnomalyFeedback(ctx context.Context, params *costexplorer.ProvideAnomalyFeedbackInput, optFns ...func(*costexplorer.Options)) (*costexplorer.ProvideAnomalyFeedbackOutput, error)
+}
+
+// DateRange is an interval of whole days; End is exclusive like the AWS API
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+	Label string // Preset name, empty for a custom range
+}
+
+// IsZero reports whether no range is set
+func (r DateRange) IsZero() bool {
+	return r.Start.IsZero() || r.End.IsZero()
+}
+
+// String returns the preset name or the inclusive dates of the range
+func (r DateRange) String() string {
+	if r.IsZero() {
+		return "Default"
+	}
+	if r.Label != "" {
+		return r.Label
+	}
+	return r.Start.Format("2006-01-02") + " to " + r.End.AddDate(0, 0, -1).Format("2006-01-02")
+}
+
+// Key identifies the range for use in cache keys
+func (r DateRange) Key() string {
+	if r.IsZero() {
+		return ""
+	}
+	return r.Start.Format("2006-01-02") + ".." + r.End.Format("2006-01-02")
+}
+
+// Query holds the settings shared by every data fetch
+type Query struct {
+	Profile string    // AWS profile the data comes from; empty for the default credentials
+	Metric  string    // Cost Explorer metric, e.g. "NetUnblendedCost"
+	Range   DateRange // Zero means each view's default range
+	Months  int       // Months of history in monthly views when no range is set
+	Filter  Filter    // Applied to every view; zero means no filter
+	// ExcludedRecordTypes are the record types, e.g. "Credit" or "Tax", left
+	// out of the per-service, per-region and per-account views and their
+	// drill-downs
+	ExcludedRecordTypes []string
+}
+
+// Key identifies the query's settings for use in cache keys
+func (q Query) Key() string {
+	return fmt.Sprintf("%s|%s|%s|%d|%s|%s", q.Profile, q.Metric, q.Range.Key(), q.Months, q.Filter, strings.Join(q.ExcludedRecordTypes, ","))
+}
+
+// FilterCondition matches costs whose dimension or tag has one of the values
+type FilterCondition struct {
+	Dimension string   // Cost Explorer dimension, e.g. "SERVICE"; empty for a tag
+	TagKey    string   // Tag key when filtering on a tag
+	Values    []string // Raw values, e.g. service names or account IDs
+	Exclude   bool     // Match costs without any of the values instead
+}
+
+// String describes the condition, e.g. "NOT REGION = us-east-1, eu-west-1"
+func (c FilterCondition) String() string {
+	field := c.Dimension
+	if field == "" {
+		field = "tag:" + c.TagKey
+	}
+	condition := fmt.Sprintf("%s = %s", field, strings.Join(c.Values, ", "))
+	if c.Exclude {
+		return "NOT " + condition
+	}
+	return condition
+}
+
+// FilterGroup is a set of conditions combined with And, or with Or when Any
+// is set
+type FilterGroup struct {
+	Conditions []FilterCondition
+	Any        bool
+}
+
+// String describes the group, e.g. "SERVICE = A AND REGION = B"
+func (g FilterGroup) String() string {
+	conditions := make([]string, len(g.Conditions))
+	for i, condition := range g.Conditions {
+		conditions[i] = condition.String()
+	}
+	return strings.Join(conditions, " "+FilterOperator(g.Any)+" ")
+}
+
+// Filter is a user-defined filter applied to every view. Its groups are
+// combined with And, or with Or when Any is set, so that filters such as
+// "(SERVICE = A AND REGION = B) OR tag:env = prod" can be built.
+type Filter struct {
+	Groups []FilterGroup
+	Any    bool
+}
+
+// IsZero reports whether the filter has no conditions
+func (f Filter) IsZero() bool {
+	for _, group := range f.Groups {
+		if len(group.Conditions) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// String describes the filter, or returns "None" when it has no conditions.
+// Groups of several conditions are parenthesized when there is more than one.
+func (f Filter) String() string {
+	if f.IsZero() {
+		return "None"
+	}
+
+	var groups []FilterGroup
+	for _, group := range f.Groups {
+		if len(group.Conditions) > 0 {
+			groups = append(groups, group)
+		}
+	}
+
+	terms := make([]string, len(groups))
+	for i, group := range groups {
+		terms[i] = group.String()
+		if len(groups) > 1 && len(group.Conditions) > 1 {
+			terms[i] = "(" + terms[i] + ")"
+		}
+	}
+	return strings.Join(terms, " "+FilterOperator(f.Any)+" ")
+}
+
+// FilterOperator returns the operator joining filter conditions or groups,
+// "OR" when any of them has to match and "AND" when all of them do
+func FilterOperator(matchAny bool) string {
+	if matchAny {
+		return "OR"
+	}
+	return "AND"
+}
+
+// DimensionFilter restricts costs to the values behind one table row, e.g.
+// the LINKED_ACCOUNT chosen when drilling into an account
+type DimensionFilter struct {
+	Dimension string   // Cost Explorer dimension, e.g. "LINKED_ACCOUNT"
+	Values    []string // Raw dimension values, e.g. an account ID
+	Label     string   // Name shown in breadcrumbs, e.g. the account name
 }
 
 // CostGroup represents a cost grouping with name and amount
@@ -28,8 +207,78 @@ type CostGroup struct {
 	Amount float64
 }
 
-// CostData represents formatted cost data for display
+// ColumnKind describes how the values of a column are interpreted
+type ColumnKind int
+
+const (
+	// KindDimension columns hold text such as a service or region name
+	KindDimension ColumnKind = iota
+	// KindMoney columns hold amounts in the column's currency unit
+	KindMoney
+	// KindPercent columns hold percentages in the 0-100 range
+	KindPercent
+	// KindQuantity columns hold plain numbers such as hours
+	KindQuantity
+)
+
+// Threshold colors the values of a column: values at or above Good are
+// green, those at or above Warn yellow and the rest red
+type Threshold struct {
+	Good float64
+	Warn float64
+}
+
+// Column describes a column of cost data
+type Column struct {
+	Title     string
+	Kind      ColumnKind
+	Unit      string     // Currency code for money columns, e.g. "USD"
+	Threshold *Threshold // Optional coloring, e.g. for utilization percentages
+}
+
+// Cell holds one value; Text is used by dimension columns and Value by the others
+type Cell struct {
+	Text  string
+	Value float64
+	Blank bool // Shown empty whatever the column kind
+}
+
+// TextCell creates a cell for a dimension column
+func TextCell(text string) Cell {
+	return Cell{Text: text}
+}
+
+// ValueCell creates a cell for a numeric column
+func ValueCell(value float64) Cell {
+	return Cell{Value: value}
+}
+
+// BlankCell creates a cell without a value, e.g. for a total that has none
+// in some column
+func BlankCell() Cell {
+	return Cell{Blank: true}
+}
+
+// DetailField is one value of a detail pane, labelled with its column's
+// title and formatted like a cell of that column
+type DetailField struct {
+	Column Column
+	Cell   Cell
+}
+
+// CostData represents typed cost data; formatting happens in the ui package
 type CostData struct {
-	Title string
-	Rows  [][]string
+	Title   string
+	Columns []Column
+	Rows    [][]Cell
+	// HighlightTop is how many of the largest values to highlight per money column
+	HighlightTop int
+	// Notice is an optional message for the status bar, e.g. about a fallback
+	Notice string
+	// RowKeys optionally holds the raw dimension values behind each row, for
+	// tables whose labels differ from the values, e.g. normalized service names
+	RowKeys [][]string
+	// Details optionally holds the full record behind each row, shown in the
+	// detail pane for the selected row
+	Details [][]DetailField
 }
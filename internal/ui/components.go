@@ -1,21 +1,19 @@
 package ui
 
 import (
+	"strings"
+
 	"github.com/rivo/tview"
 )
 
-// CreateMenu creates the main navigation menu
-func CreateMenu(onSelect func(string)) *tview.List {
+// footerHelp is the help text shown for the global keys
+const footerHelp = "Press 'q' to quit | 'j/k' to navigate | Enter to select & enter table | h/l to scroll columns | Tab to return to menu | PgUp/PgDn to page | 'r' to retry | 'm' to switch metric | 'd' for dates | 'f' to filter | 'x' for record types | 'u' for currency | 'P' for profiles"
+
+// CreateMenu creates the main navigation menu with the given items
+func CreateMenu(menuItems []string, onSelect func(string)) *tview.List {
 	menu := tview.NewList()
 	menu.SetBorder(true).SetTitle("💸 Cost Explorer")
 
-	menuItems := []string{
-		"Dashboard",
-		"By Service",
-		"By Region",
-		"By Usage Type",
-	}
-
 	for _, item := range menuItems {
 		menu.AddItem(item, "", 0, nil)
 	}
@@ -30,10 +28,19 @@ func CreateMainTable() *tview.Table {
 	table := tview.NewTable()
 	table.SetBorder(true).SetTitle("Cost Data")
 	table.SetSelectable(true, false) // Allow row selection but not column selection
-	table.SetFixed(1, 0)             // Fix the first row as header
+	table.SetFixed(1, 1)             // Fix the header row and the name column while scrolling
 	return table
 }
 
+// CreateDetailPane creates the text view showing the details of the selected row
+func CreateDetailPane() *tview.TextView {
+	pane := tview.NewTextView()
+	pane.SetBorder(true).SetTitle("Details")
+	pane.SetDynamicColors(true)
+	pane.SetWrap(true)
+	return pane
+}
+
 // CreateHeader creates the header text view
 func CreateHeader() *tview.TextView {
 	header := tview.NewTextView()
@@ -44,12 +51,29 @@ func CreateHeader() *tview.TextView {
 	return header
 }
 
+// CreateStatusBar creates the status view used for fetch errors and progress
+func CreateStatusBar() *tview.TextView {
+	status := tview.NewTextView()
+	status.SetBorder(true).SetTitle("Status")
+	status.SetDynamicColors(true)
+	return status
+}
+
 // CreateFooter creates the footer text view with help text
 func CreateFooter() *tview.TextView {
 	footer := tview.NewTextView()
 	footer.SetBorder(true)
-	footer.SetText("Press 'q' to quit | 'j/k' to navigate | Enter to select & enter table | Tab to return to menu | PgUp/PgDn to page")
+	footer.SetText(footerHelp)
 	footer.SetTextAlign(tview.AlignCenter)
 	footer.SetDynamicColors(true)
 	return footer
 }
+
+// SetFooterHints shows the global help text followed by view-specific key hints
+func SetFooterHints(footer *tview.TextView, hints []string) {
+	if len(hints) == 0 {
+		footer.SetText(footerHelp)
+		return
+	}
+	footer.SetText(footerHelp + " | " + strings.Join(hints, " | "))
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"cost-explorer/internal/types"
+
+	"github.com/rivo/tview"
+)
+
+// detailPaneHeight is the height of the detail pane while it is shown
+const detailPaneHeight = 14
+
+// detailLabelWidth and detailValueWidth align the fields in two columns
+const (
+	detailLabelWidth = 24
+	detailValueWidth = 32
+)
+
+// ShowDetailPane shows the fields of the selected row below the table, or
+// hides the pane when there are none
+func ShowDetailPane(state *types.AppState, fields []types.DetailField) {
+	if len(fields) == 0 {
+		state.Content.ResizeItem(state.DetailPane, 0, 0)
+		state.DetailPane.Clear()
+		return
+	}
+
+	state.Content.ResizeItem(state.DetailPane, detailPaneHeight, 0)
+	state.DetailPane.SetText(FormatDetails(fields))
+	state.DetailPane.ScrollToBeginning()
+}
+
+// FormatDetails renders fields two to a line, leaving out those without a value
+func FormatDetails(fields []types.DetailField) string {
+	var lines []string
+	var line string
+	count := 0
+	for _, field := range fields {
+		value := FormatCell(field.Column, field.Cell)
+		if value == "" {
+			continue
+		}
+
+		label := fmt.Sprintf("%-*s", detailLabelWidth, field.Column.Title+":")
+		entry := fmt.Sprintf("[yellow]%s[-] %s", label, tview.Escape(fmt.Sprintf("%-*s", detailValueWidth, value)))
+		if count%2 == 0 {
+			line = entry
+		} else {
+			lines = append(lines, strings.TrimRight(line+" "+entry, " "))
+		}
+		count++
+	}
+	if count%2 == 1 {
+		lines = append(lines, strings.TrimRight(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"cost-explorer/internal/types"
+
+	"github.com/rivo/tview"
+)
+
+// tagField is the field option of the filter builder used for tags
+const tagField = "Tag"
+
+// maxSuggestions limits the autocomplete entries shown for a value
+const maxSuggestions = 10
+
+// CreateFilterPanel creates the filter builder. fields lists the dimensions
+// that can be filtered on; a tag option is added after them. complete
+// returns the known values of a dimension for autocompletion. Conditions are
+// added to the last group; "New group" starts another, so that groups of
+// conditions can be combined. The returned form is the primitive to focus.
+func CreateFilterPanel(filter types.Filter, fields []string, complete func(dimension string) []string, onApply func(types.Filter), onCancel func()) (tview.Primitive, *tview.Form) {
+	// Work on a copy so cancelling leaves the active filter untouched
+	groups := make([]types.FilterGroup, len(filter.Groups))
+	for i, group := range filter.Groups {
+		group.Conditions = append([]types.FilterCondition(nil), group.Conditions...)
+		groups[i] = group
+	}
+	filter.Groups = groups
+	lastGroup := func() *types.FilterGroup {
+		if len(filter.Groups) == 0 {
+			filter.Groups = append(filter.Groups, types.FilterGroup{})
+		}
+		return &filter.Groups[len(filter.Groups)-1]
+	}
+
+	conditions := tview.NewTextView().SetDynamicColors(true)
+	conditions.SetBorder(true).SetTitle("Conditions")
+	showConditions := func() {
+		if filter.IsZero() && len(filter.Groups) <= 1 {
+			conditions.SetText("[gray]No conditions; every cost is included[-]")
+			return
+		}
+		var lines []string
+		for i, group := range filter.Groups {
+			line := fmt.Sprintf("Group %d: %s", i+1, tview.Escape(group.String()))
+			if len(group.Conditions) == 0 {
+				line = fmt.Sprintf("Group %d: [gray]empty; add conditions to it[-]", i+1)
+			}
+			if i > 0 {
+				line = fmt.Sprintf("[gray]%s[-] %s", types.FilterOperator(filter.Any), line)
+			}
+			lines = append(lines, line)
+		}
+		conditions.SetText(strings.Join(lines, "\n"))
+	}
+	showConditions()
+
+	options := append(append([]string(nil), fields...), tagField)
+	combineOptions := []string{"All (AND)", "Any (OR)"}
+	combineIndex := func(matchAny bool) int {
+		if matchAny {
+			return 1
+		}
+		return 0
+	}
+
+	form := tview.NewForm()
+	form.AddDropDown("Groups", combineOptions, combineIndex(filter.Any), func(_ string, index int) {
+		filter.Any = index == 1
+		showConditions()
+	})
+	form.AddDropDown("In group", combineOptions, combineIndex(lastGroup().Any), func(_ string, index int) {
+		lastGroup().Any = index == 1
+		showConditions()
+	})
+	form.AddDropDown("Field", options, 0, nil)
+	form.AddInputField("Tag key", "", 30, nil, nil)
+	form.AddDropDown("Mode", []string{"Include", "Exclude"}, 0, nil)
+	form.AddInputField("Values", "", 40, nil, nil)
+
+	field := form.GetFormItemByLabel("Field").(*tview.DropDown)
+	tagKey := form.GetFormItemByLabel("Tag key").(*tview.InputField)
+	mode := form.GetFormItemByLabel("Mode").(*tview.DropDown)
+	inGroup := form.GetFormItemByLabel("In group").(*tview.DropDown)
+	values := form.GetFormItemByLabel("Values").(*tview.InputField)
+
+	// Complete the value after the last comma from the field's known values
+	values.SetAutocompleteFunc(func(text string) []string {
+		_, option := field.GetCurrentOption()
+		if option == tagField {
+			return nil
+		}
+
+		prefix, current := "", text
+		if index := strings.LastIndex(text, ","); index >= 0 {
+			prefix, current = text[:index+1]+" ", strings.TrimSpace(text[index+1:])
+		}
+		if current == "" {
+			return nil
+		}
+
+		var entries []string
+		for _, value := range complete(option) {
+			if strings.HasPrefix(strings.ToLower(value), strings.ToLower(current)) {
+				entries = append(entries, prefix+value)
+				if len(entries) == maxSuggestions {
+					break
+				}
+			}
+		}
+		return entries
+	})
+
+	form.AddButton("Add", func() {
+		condition := types.FilterCondition{}
+		_, option := field.GetCurrentOption()
+		if option == tagField {
+			condition.TagKey = strings.TrimSpace(tagKey.GetText())
+			if condition.TagKey == "" {
+				return
+			}
+		} else {
+			condition.Dimension = option
+		}
+		for _, value := range strings.Split(values.GetText(), ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				condition.Values = append(condition.Values, value)
+			}
+		}
+		if len(condition.Values) == 0 {
+			return
+		}
+		index, _ := mode.GetCurrentOption()
+		condition.Exclude = index == 1
+
+		group := lastGroup()
+		group.Conditions = append(group.Conditions, condition)
+		values.SetText("")
+		showConditions()
+	})
+	form.AddButton("New group", func() {
+		if len(lastGroup().Conditions) == 0 {
+			return
+		}
+		filter.Groups = append(filter.Groups, types.FilterGroup{})
+		inGroup.SetCurrentOption(0)
+		showConditions()
+	})
+	form.AddButton("Remove", func() {
+		// Drop the last condition, and its group once empty
+		group := lastGroup()
+		if len(group.Conditions) > 0 {
+			group.Conditions = group.Conditions[:len(group.Conditions)-1]
+		}
+		if len(group.Conditions) == 0 && len(filter.Groups) > 1 {
+			filter.Groups = filter.Groups[:len(filter.Groups)-1]
+		}
+		inGroup.SetCurrentOption(combineIndex(lastGroup().Any))
+		showConditions()
+	})
+	form.AddButton("Clear", func() {
+		filter.Groups = nil
+		inGroup.SetCurrentOption(0)
+		showConditions()
+	})
+	form.AddButton("Apply", func() {
+		// Leave out groups that never got a condition
+		var groups []types.FilterGroup
+		for _, group := range filter.Groups {
+			if len(group.Conditions) > 0 {
+				groups = append(groups, group)
+			}
+		}
+		filter.Groups = groups
+		onApply(filter)
+	})
+	form.AddButton("Cancel", onCancel)
+	form.SetCancelFunc(onCancel)
+	form.SetBorder(true).SetTitle("Filter")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(conditions, 7, 0, false).
+		AddItem(form, 0, 1, true)
+	return layout, form
+}
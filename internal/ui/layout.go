@@ -9,17 +9,24 @@ import (
 // SetupGrid configures the main grid layout
 func SetupGrid(state *types.AppState) *tview.Grid {
 	grid := tview.NewGrid().
-		SetRows(3, 0, 3).
+		SetRows(3, 0, 3, 3).
 		SetColumns(25, 0).
 		SetBorders(false)
 
-	// Static items (header and footer span both columns)
+	// Static items (header, status and footer span both columns)
 	grid.AddItem(state.Header, 0, 0, 1, 2, 0, 0, false)
-	grid.AddItem(state.Footer, 2, 0, 1, 2, 0, 0, false)
+	grid.AddItem(state.StatusBar, 2, 0, 1, 2, 0, 0, false)
+	grid.AddItem(state.Footer, 3, 0, 1, 2, 0, 0, false)
+
+	// The table shares its column with the detail pane, hidden until a view
+	// with row details is shown
+	state.Content = tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(state.MainTable, 0, 1, false).
+		AddItem(state.DetailPane, 0, 0, false)
 
 	// Main layout (2 columns: menu + table)
 	grid.AddItem(state.Menu, 1, 0, 1, 1, 0, 80, true)
-	grid.AddItem(state.MainTable, 1, 1, 1, 1, 0, 80, false)
+	grid.AddItem(state.Content, 1, 1, 1, 1, 0, 80, false)
 
 	return grid
 }
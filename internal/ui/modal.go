@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"slices"
+
+	"github.com/rivo/tview"
+)
+
+// CenteredModal wraps a primitive in a centered box of the given size
+func CenteredModal(content tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(content, height, 1, true).
+			AddItem(nil, 0, 1, false), width, 1, true).
+		AddItem(nil, 0, 1, false)
+}
+
+// CreateDateRangeForm creates the date range picker. The preset dropdown lists
+// "Custom" followed by presets; onApply receives the chosen preset label, or
+// an empty string with the typed dates for a custom range.
+func CreateDateRangeForm(presets []string, current, from, to string, onApply func(preset, from, to string), onReset, onCancel func()) *tview.Form {
+	options := append([]string{"Custom"}, presets...)
+	selected := 0
+	for i, option := range options {
+		if option == current {
+			selected = i
+		}
+	}
+
+	form := tview.NewForm()
+	form.AddDropDown("Preset", options, selected, nil)
+	form.AddInputField("From (YYYY-MM-DD)", from, 12, nil, nil)
+	form.AddInputField("To (YYYY-MM-DD)", to, 12, nil, nil)
+
+	form.AddButton("Apply", func() {
+		index, _ := form.GetFormItemByLabel("Preset").(*tview.DropDown).GetCurrentOption()
+		from := form.GetFormItemByLabel("From (YYYY-MM-DD)").(*tview.InputField).GetText()
+		to := form.GetFormItemByLabel("To (YYYY-MM-DD)").(*tview.InputField).GetText()
+		preset := ""
+		if index > 0 {
+			preset = options[index]
+		}
+		onApply(preset, from, to)
+	})
+	form.AddButton("Reset", onReset)
+	form.AddButton("Cancel", onCancel)
+	form.SetCancelFunc(onCancel)
+
+	form.SetBorder(true).SetTitle("Date Range")
+	return form
+}
+
+// CreatePickerList creates a modal list of options with current preselected.
+// onSelect receives the chosen option; Escape calls onCancel.
+func CreatePickerList(title string, options []string, current string, onSelect func(string), onCancel func()) *tview.List {
+	list := tview.NewList().ShowSecondaryText(false)
+	for _, option := range options {
+		option := option
+		list.AddItem(option, "", 0, func() {
+			onSelect(option)
+		})
+		if option == current {
+			list.SetCurrentItem(list.GetItemCount() - 1)
+		}
+	}
+
+	list.SetDoneFunc(onCancel)
+	list.SetBorder(true).SetTitle(title)
+	return list
+}
+
+// CreateRecordTypeForm creates the form choosing which record types count
+// toward the per-service, per-region and per-account views. A checked box
+// includes the record type; onApply receives the unchecked ones.
+func CreateRecordTypeForm(recordTypes, excluded []string, onApply func(excluded []string), onReset, onCancel func()) *tview.Form {
+	form := tview.NewForm().SetItemPadding(0)
+	for _, recordType := range recordTypes {
+		form.AddCheckbox(recordType, !slices.Contains(excluded, recordType), nil)
+	}
+
+	form.AddButton("Apply", func() {
+		var unchecked []string
+		for _, recordType := range recordTypes {
+			if !form.GetFormItemByLabel(recordType).(*tview.Checkbox).IsChecked() {
+				unchecked = append(unchecked, recordType)
+			}
+		}
+		onApply(unchecked)
+	})
+	form.AddButton("Reset", onReset)
+	form.AddButton("Cancel", onCancel)
+	form.SetCancelFunc(onCancel)
+
+	form.SetBorder(true).SetTitle("Record Types")
+	return form
+}
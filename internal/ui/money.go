@@ -0,0 +1,244 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"cost-explorer/internal/config"
+)
+
+// moneyLocale holds the conventions of a locale for writing amounts
+type moneyLocale struct {
+	thousands string
+	decimal   string
+	suffix    bool // Symbol after the amount, separated by a space
+}
+
+// moneyLocales are the locales accepted in the currency config
+var moneyLocales = map[string]moneyLocale{
+	"en-US": {thousands: ",", decimal: "."},
+	"en-GB": {thousands: ",", decimal: "."},
+	"en-IE": {thousands: ",", decimal: "."},
+	"ja-JP": {thousands: ",", decimal: "."},
+	"de-DE": {thousands: ".", decimal: ",", suffix: true},
+	"es-ES": {thousands: ".", decimal: ",", suffix: true},
+	"it-IT": {thousands: ".", decimal: ",", suffix: true},
+	"nl-NL": {thousands: ".", decimal: ",", suffix: true},
+	"fr-FR": {thousands: " ", decimal: ",", suffix: true},
+	"de-CH": {thousands: "'", decimal: ".", suffix: true},
+}
+
+// defaultLocale is used when the config sets none
+const defaultLocale = "en-US"
+
+// currencySymbols replace the codes of common currencies; others are written
+// as their code
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+	"JPY": "¥",
+	"INR": "₹",
+	"CAD": "CA$",
+	"AUD": "A$",
+}
+
+// currencyDecimals overrides the two decimals shown for most currencies
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+}
+
+// compactSuffixes abbreviate amounts of at least their size in compact mode
+var compactSuffixes = []struct {
+	size   float64
+	suffix string
+}{
+	{1e9, "B"},
+	{1e6, "M"},
+	{1e3, "k"},
+}
+
+// moneyFormat is the formatting applied by FormatMoney
+type moneyFormat struct {
+	locale    moneyLocale
+	compact   bool
+	reporting string
+	rates     map[string]float64
+	convert   bool // Whether amounts are shown in the reporting currency
+}
+
+var (
+	moneyMu sync.RWMutex
+	money   = moneyFormat{locale: moneyLocales[defaultLocale]}
+)
+
+// SetMoneyFormat applies the currency config to every amount formatted
+// afterwards. Conversion to the reporting currency starts enabled when one is
+// configured.
+func SetMoneyFormat(currency config.Currency) error {
+	name := currency.Locale
+	if name == "" {
+		name = defaultLocale
+	}
+	locale, exists := moneyLocales[name]
+	if !exists {
+		return fmt.Errorf("unknown locale %q, expected one of %s", name, strings.Join(MoneyLocales(), ", "))
+	}
+
+	for code, rate := range currency.Rates {
+		if rate <= 0 {
+			return fmt.Errorf("conversion rate for %s must be positive", code)
+		}
+	}
+	if currency.Reporting != "" && len(currency.Rates) == 0 {
+		return fmt.Errorf("reporting currency %s needs conversion rates", currency.Reporting)
+	}
+
+	moneyMu.Lock()
+	defer moneyMu.Unlock()
+	money = moneyFormat{
+		locale:    locale,
+		compact:   currency.Compact,
+		reporting: currency.Reporting,
+		rates:     currency.Rates,
+		convert:   currency.Reporting != "",
+	}
+	return nil
+}
+
+// MoneyLocales returns the names of the supported locales, sorted
+func MoneyLocales() []string {
+	names := make([]string, 0, len(moneyLocales))
+	for name := range moneyLocales {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ToggleReportingCurrency switches between showing amounts in the reporting
+// currency and in the currency they were billed in. It reports whether
+// amounts are now converted, and whether a reporting currency is configured
+// at all; without one it does nothing.
+func ToggleReportingCurrency() (converted, configured bool) {
+	moneyMu.Lock()
+	defer moneyMu.Unlock()
+	if money.reporting == "" {
+		return false, false
+	}
+	money.convert = !money.convert
+	return money.convert, true
+}
+
+// ReportingCurrency returns the currency amounts are converted to, or an
+// empty string when they are shown as billed
+func ReportingCurrency() string {
+	moneyMu.RLock()
+	defer moneyMu.RUnlock()
+	if !money.convert {
+		return ""
+	}
+	return money.reporting
+}
+
+// FormatMoney formats an amount in the given currency unit, converted to the
+// reporting currency when one is active and has a rate for the unit
+func FormatMoney(amount float64, unit string) string {
+	moneyMu.RLock()
+	format := money
+	moneyMu.RUnlock()
+
+	// Cost Explorer omits the unit for some USD amounts
+	if unit == "" {
+		unit = "USD"
+	}
+	if format.convert && unit != format.reporting {
+		if rate, exists := format.rates[unit]; exists {
+			amount *= rate
+			unit = format.reporting
+		}
+	}
+
+	decimals, exists := currencyDecimals[unit]
+	if !exists {
+		decimals = 2
+	}
+	sign, amount := splitSign(amount, decimals)
+	number := groupedNumber(amount, decimals, format.locale)
+	if format.compact {
+		number = compactNumber(amount, number, format.locale)
+	}
+
+	symbol, exists := currencySymbols[unit]
+	switch {
+	case !exists:
+		return fmt.Sprintf("%s%s %s", sign, number, unit)
+	case format.locale.suffix:
+		return fmt.Sprintf("%s%s %s", sign, number, symbol)
+	}
+	return sign + symbol + number
+}
+
+// formatNumber formats a plain number, such as a percentage or quantity,
+// with the locale's separators
+func formatNumber(value float64, decimals int) string {
+	moneyMu.RLock()
+	locale := money.locale
+	moneyMu.RUnlock()
+
+	sign, value := splitSign(value, decimals)
+	return sign + groupedNumber(value, decimals, locale)
+}
+
+// splitSign returns the sign and magnitude of an amount. Amounts that round
+// to zero at the given decimals are zero, so they are not written as "-0.00".
+func splitSign(amount float64, decimals int) (string, float64) {
+	if math.Abs(amount) < 0.5/math.Pow10(decimals) {
+		return "", 0
+	}
+	if amount < 0 {
+		return "-", -amount
+	}
+	return "", amount
+}
+
+// compactNumber abbreviates amounts of a thousand or more to one decimal,
+// e.g. "12.3k", returning number unchanged for smaller amounts
+func compactNumber(amount float64, number string, locale moneyLocale) string {
+	for i, compact := range compactSuffixes {
+		if amount < compact.size {
+			continue
+		}
+		// Rounding may carry into the next size, e.g. 999,960 is "1.0M"
+		scaled := math.Round(amount/compact.size*10) / 10
+		if scaled >= 1000 && i > 0 {
+			compact = compactSuffixes[i-1]
+			scaled = math.Round(amount/compact.size*10) / 10
+		}
+		return groupedNumber(scaled, 1, locale) + compact.suffix
+	}
+	return number
+}
+
+// groupedNumber writes a non-negative amount with the locale's separators
+func groupedNumber(amount float64, decimals int, locale moneyLocale) string {
+	digits := strconv.FormatFloat(amount, 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var grouped strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteString(locale.thousands)
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString(locale.decimal)
+		grouped.WriteString(fraction)
+	}
+	return grouped.String()
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"testing"
+
+	"cost-explorer/internal/config"
+)
+
+// useMoneyFormat applies a currency config for the rest of the test
+func useMoneyFormat(t *testing.T, currency config.Currency) {
+	t.Helper()
+	if err := SetMoneyFormat(currency); err != nil {
+		t.Fatalf("SetMoneyFormat: %v", err)
+	}
+	t.Cleanup(func() { SetMoneyFormat(config.Currency{}) })
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency config.Currency
+		amount   float64
+		unit     string
+		want     string
+	}{
+		{"default", config.Currency{}, 1234.5, "USD", "$1,234.50"},
+		{"missing unit is USD", config.Currency{}, 12, "", "$12.00"},
+		{"negative", config.Currency{}, -12.5, "USD", "-$12.50"},
+		{"rounds to zero", config.Currency{}, -0.004, "USD", "$0.00"},
+		{"rounds up", config.Currency{}, -0.006, "USD", "-$0.01"},
+		{"millions", config.Currency{}, 1234567.891, "USD", "$1,234,567.89"},
+		{"no decimals", config.Currency{}, 1234.4, "JPY", "¥1,234"},
+		{"no decimals rounds to zero", config.Currency{}, -0.4, "JPY", "¥0"},
+		{"unknown symbol", config.Currency{}, 5, "SEK", "5.00 SEK"},
+		{"de-DE", config.Currency{Locale: "de-DE"}, 1234.5, "EUR", "1.234,50 €"},
+		{"de-DE negative", config.Currency{Locale: "de-DE"}, -1234.5, "EUR", "-1.234,50 €"},
+		{"fr-FR", config.Currency{Locale: "fr-FR"}, 1234.5, "EUR", "1 234,50 €"},
+		{"de-CH", config.Currency{Locale: "de-CH"}, 1234.5, "CHF", "1'234.50 CHF"},
+		{"compact", config.Currency{Compact: true}, 12345, "USD", "$12.3k"},
+		{"compact small", config.Currency{Compact: true}, 999.5, "USD", "$999.50"},
+		{"compact carries", config.Currency{Compact: true}, 999960, "USD", "$1.0M"},
+		{"compact de-DE", config.Currency{Locale: "de-DE", Compact: true}, -2500000, "EUR", "-2,5M €"},
+		{"converted", config.Currency{Reporting: "EUR", Rates: map[string]float64{"USD": 0.5}}, 100, "USD", "€50.00"},
+		{"no rate", config.Currency{Reporting: "EUR", Rates: map[string]float64{"USD": 0.5}}, 100, "GBP", "£100.00"},
+		{"already reporting", config.Currency{Reporting: "EUR", Rates: map[string]float64{"USD": 0.5}}, 100, "EUR", "€100.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMoneyFormat(t, tt.currency)
+			if got := FormatMoney(tt.amount, tt.unit); got != tt.want {
+				t.Errorf("FormatMoney(%v, %q) = %q, want %q", tt.amount, tt.unit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleReportingCurrency(t *testing.T) {
+	useMoneyFormat(t, config.Currency{Reporting: "EUR", Rates: map[string]float64{"USD": 0.5}})
+
+	if converted, configured := ToggleReportingCurrency(); converted || !configured {
+		t.Fatalf("toggle = %t, %t, want billed currency", converted, configured)
+	}
+	if got := FormatMoney(100, "USD"); got != "$100.00" {
+		t.Errorf("billed amount = %q, want $100.00", got)
+	}
+	if converted, _ := ToggleReportingCurrency(); !converted {
+		t.Fatalf("second toggle did not convert again")
+	}
+	if got := FormatMoney(100, "USD"); got != "€50.00" {
+		t.Errorf("converted amount = %q, want €50.00", got)
+	}
+}
+
+func TestSetMoneyFormatErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency config.Currency
+	}{
+		{"unknown locale", config.Currency{Locale: "xx-XX"}},
+		{"reporting without rates", config.Currency{Reporting: "EUR"}},
+		{"negative rate", config.Currency{Reporting: "EUR", Rates: map[string]float64{"USD": -1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetMoneyFormat(tt.currency); err == nil {
+				SetMoneyFormat(config.Currency{})
+				t.Errorf("SetMoneyFormat(%+v) succeeded, want an error", tt.currency)
+			}
+		})
+	}
+}
+
+func TestGroupedNumber(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		decimals int
+		locale   string
+		want     string
+	}{
+		{0, 2, "en-US", "0.00"},
+		{999, 2, "en-US", "999.00"},
+		{1000, 2, "en-US", "1,000.00"},
+		{123456.784, 2, "en-US", "123,456.78"},
+		{999.999, 2, "en-US", "1,000.00"},
+		{1234567, 0, "en-US", "1,234,567"},
+		{1234567.5, 1, "de-DE", "1.234.567,5"},
+		{1234.5, 2, "fr-FR", "1 234,50"},
+	}
+
+	for _, tt := range tests {
+		if got := groupedNumber(tt.amount, tt.decimals, moneyLocales[tt.locale]); got != tt.want {
+			t.Errorf("groupedNumber(%v, %d, %s) = %q, want %q", tt.amount, tt.decimals, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestCompactNumber(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{999.99, "unchanged"},
+		{1000, "1.0k"},
+		{12345, "12.3k"},
+		{999949, "999.9k"},
+		{999960, "1.0M"},
+		{2500000, "2.5M"},
+		{1234567890, "1.2B"},
+	}
+
+	for _, tt := range tests {
+		if got := compactNumber(tt.amount, "unchanged", moneyLocales["en-US"]); got != tt.want {
+			t.Errorf("compactNumber(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -2,8 +2,7 @@ package ui
 
 import (
 	"log"
-	"strconv"
-	"strings"
+	"sort"
 
 	"cost-explorer/internal/types"
 
@@ -24,7 +23,7 @@ func PopulateTable(table *tview.Table, data types.CostData) {
 	table.SetTitle(data.Title)
 	log.Printf("Table cleared and title set to: %s", data.Title)
 
-	if len(data.Rows) == 0 {
+	if len(data.Columns) == 0 {
 		table.SetCell(0, 0, tview.NewTableCell("No data available").
 			SetAlign(tview.AlignCenter).
 			SetSelectable(false))
@@ -32,40 +31,44 @@ func PopulateTable(table *tview.Table, data types.CostData) {
 		return
 	}
 
-	// Add header row if present
-	if len(data.Rows) > 0 && len(data.Rows[0]) > 0 {
-		log.Printf("Adding header row with %d columns", len(data.Rows[0]))
-		for col, cell := range data.Rows[0] {
-			table.SetCell(0, col, tview.NewTableCell("[yellow::b]"+cell+"[-::-]").
-				SetAlign(tview.AlignCenter).
-				SetSelectable(false))
-		}
+	// Add header row
+	log.Printf("Adding header row with %d columns", len(data.Columns))
+	for col, column := range data.Columns {
+		table.SetCell(0, col, tview.NewTableCell("[yellow::b]"+column.Title+"[-::-]").
+			SetAlign(tview.AlignCenter).
+			SetSelectable(false))
 	}
 
-	// Identify top 3 costs for each month column (for service data highlighting)
-	topCostsByColumn := make(map[int]map[int]bool) // column -> row -> isTop3
-	if strings.Contains(data.Title, "Services") && len(data.Rows) > 1 {
-		topCostsByColumn = findTopCostsPerColumn(data.Rows)
-	}
+	// Identify top costs for each money column
+	topCostsByColumn := findTopCostsPerColumn(data, data.HighlightTop)
 
-	// Add data rows
-	for row := 1; row < len(data.Rows); row++ {
-		if len(data.Rows[row]) == 0 {
+	// Add data rows; table row 0 is the header
+	for i, cells := range data.Rows {
+		row := i + 1
+		if len(cells) == 0 {
 			log.Printf("WARNING: Row %d is empty, skipping", row)
 			continue
 		}
 
-		for col, cell := range data.Rows[row] {
+		for col, cell := range cells {
+			if col >= len(data.Columns) {
+				break
+			}
+			column := data.Columns[col]
 			color := "[white]"
 
-			// Check if this cell should be highlighted as top 3 cost
-			if topCostsByColumn[col] != nil && topCostsByColumn[col][row] {
+			// Color by threshold, mark credits and refunds, or highlight the top costs
+			if column.Threshold != nil && !cell.Blank {
+				color = thresholdColor(*column.Threshold, cell.Value)
+			} else if column.Kind == types.KindMoney && !cell.Blank && cell.Value < 0 {
+				color = "[green]"
+			} else if topCostsByColumn[col] != nil && topCostsByColumn[col][i] {
 				color = "[yellow]"
-			} else if strings.HasPrefix(cell, "$") {
-				color = "[-]" // Default color for dollar amounts
+			} else if column.Kind == types.KindMoney {
+				color = "[-]" // Default color for money amounts
 			}
 
-			table.SetCell(row, col, tview.NewTableCell(color+cell+"[-]").
+			table.SetCell(row, col, tview.NewTableCell(color+FormatCell(column, cell)+"[-]").
 				SetAlign(tview.AlignLeft).
 				SetSelectable(true))
 		}
@@ -74,24 +77,50 @@ func PopulateTable(table *tview.Table, data types.CostData) {
 	log.Printf("Table populated successfully with %d rows", len(data.Rows))
 }
 
-// parseAmount extracts the numeric value from a cost string
-func parseAmount(amountStr string) float64 {
-	// Remove $ and commas, then parse
-	cleaned := strings.ReplaceAll(strings.TrimPrefix(amountStr, "$"), ",", "")
-	amount, _ := strconv.ParseFloat(cleaned, 64)
-	return amount
+// thresholdColor returns the color tag for a value under a threshold
+func thresholdColor(threshold types.Threshold, value float64) string {
+	switch {
+	case value >= threshold.Good:
+		return "[green]"
+	case value >= threshold.Warn:
+		return "[yellow]"
+	default:
+		return "[red]"
+	}
+}
+
+// FormatCell renders a cell according to its column kind
+func FormatCell(column types.Column, cell types.Cell) string {
+	if cell.Blank {
+		return ""
+	}
+
+	switch column.Kind {
+	case types.KindMoney:
+		return FormatMoney(cell.Value, column.Unit)
+	case types.KindPercent:
+		return formatNumber(cell.Value, 1) + "%"
+	case types.KindQuantity:
+		return formatNumber(cell.Value, 2)
+	default:
+		return cell.Text
+	}
 }
 
-// findTopCostsPerColumn identifies the top 3 costs in each month column for highlighting
-func findTopCostsPerColumn(rows [][]string) map[int]map[int]bool {
+// findTopCostsPerColumn identifies the top n values in each money column for
+// highlighting. The result maps column -> data row index -> isTop.
+func findTopCostsPerColumn(data types.CostData, n int) map[int]map[int]bool {
 	result := make(map[int]map[int]bool)
 
-	if len(rows) < 2 {
+	if n <= 0 || len(data.Rows) == 0 {
 		return result
 	}
 
-	// Skip first column (service names) and process cost columns
-	for col := 1; col < len(rows[0]); col++ {
+	for col, column := range data.Columns {
+		if column.Kind != types.KindMoney {
+			continue
+		}
+
 		// Collect all costs for this column with their row indices
 		type costRow struct {
 			amount float64
@@ -99,27 +128,20 @@ func findTopCostsPerColumn(rows [][]string) map[int]map[int]bool {
 		}
 
 		var costs []costRow
-		for row := 1; row < len(rows); row++ {
-			if col < len(rows[row]) {
-				amount := parseAmount(rows[row][col])
-				if amount > 0 {
-					costs = append(costs, costRow{amount: amount, row: row})
-				}
+		for row, cells := range data.Rows {
+			if col < len(cells) && cells[col].Value > 0 {
+				costs = append(costs, costRow{amount: cells[col].Value, row: row})
 			}
 		}
 
 		// Sort by amount descending
-		for i := 0; i < len(costs)-1; i++ {
-			for j := i + 1; j < len(costs); j++ {
-				if costs[i].amount < costs[j].amount {
-					costs[i], costs[j] = costs[j], costs[i]
-				}
-			}
-		}
+		sort.Slice(costs, func(i, j int) bool {
+			return costs[i].amount > costs[j].amount
+		})
 
-		// Mark top 3 costs for this column
+		// Mark top costs for this column
 		result[col] = make(map[int]bool)
-		for i := 0; i < len(costs) && i < 3; i++ {
+		for i := 0; i < len(costs) && i < n; i++ {
 			result[col][costs[i].row] = true
 		}
 	}
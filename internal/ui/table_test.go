@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"testing"
+
+	"cost-explorer/internal/config"
+	"cost-explorer/internal/types"
+)
+
+func TestFormatCell(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		column types.Column
+		cell   types.Cell
+		want   string
+	}{
+		{"blank", "en-US", types.Column{Kind: types.KindMoney}, types.BlankCell(), ""},
+		{"text", "en-US", types.Column{}, types.TextCell("S3"), "S3"},
+		{"money", "de-DE", types.Column{Kind: types.KindMoney, Unit: "EUR"}, types.ValueCell(1234.5), "1.234,50 €"},
+		{"percent", "en-US", types.Column{Kind: types.KindPercent}, types.ValueCell(12.54), "12.5%"},
+		{"percent de-DE", "de-DE", types.Column{Kind: types.KindPercent}, types.ValueCell(12.54), "12,5%"},
+		{"percent rounds to zero", "en-US", types.Column{Kind: types.KindPercent}, types.ValueCell(-0.04), "0.0%"},
+		{"quantity de-DE", "de-DE", types.Column{Kind: types.KindQuantity}, types.ValueCell(1234.5), "1.234,50"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useMoneyFormat(t, config.Currency{Locale: tt.locale})
+			if got := FormatCell(tt.column, tt.cell); got != tt.want {
+				t.Errorf("FormatCell = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}